@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/backup"
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// backupResult is the machine-readable result of backing up a cluster
+type backupResult struct {
+	Name       string `json:"name"`
+	Datastore  string `json:"datastore"`
+	K3sVersion string `json:"k3s_version,omitempty"`
+	File       string `json:"file"`
+}
+
+// NewBackupCmd creates a command to back up the state of a cluster
+func NewBackupCmd() *cobra.Command {
+	var outputFile string
+
+	backupCmd := &cobra.Command{
+		Use:   "backup <name>",
+		Short: "Back up the state of a cluster to a file",
+		Long: `Back up a cluster into a tar.gz file holding its kubeconfig, mpkube metadata,
+server token and k3s datastore. Restore it with 'mpkube restore'.
+
+Clusters using embedded etcd are snapshotted with 'k3s etcd-snapshot' while
+running. Clusters using the default sqlite datastore have k3s stopped for a
+moment so the database is copied consistently.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := expandPaths(&outputFile); err != nil {
+				return err
+			}
+			return backupCluster(args[0], outputFile)
+		},
+	}
+
+	backupCmd.Flags().StringVarP(&outputFile, "file", "f", "", "File to write the backup to (default <name>-<timestamp>.tar.gz)")
+
+	return backupCmd
+}
+
+// backupCluster writes a backup of a cluster to outputFile
+func backupCluster(name string, outputFile string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	if outputFile == "" {
+		outputFile = fmt.Sprintf("%s-%s.tar.gz", name, time.Now().Format("20060102-150405"))
+	}
+
+	kubeconfig, err := k3s.GetKubeconfig(mp, name)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+
+	bundle := &backup.Bundle{
+		Manifest: backup.Manifest{
+			Version:   backup.FormatVersion,
+			Name:      name,
+			CreatedAt: time.Now().UTC(),
+		},
+		Kubeconfig: []byte(kubeconfig),
+	}
+
+	dataDir := ""
+	md, err := metadata.Load(name)
+	switch {
+	case err == nil:
+		dataDir = md.DataDir
+		if bundle.Metadata, err = json.MarshalIndent(md, "", "  "); err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if version, err := k3s.GetVersion(mp, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		bundle.Manifest.K3sVersion = version
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mpkube-backup-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	bundle.DataPath = filepath.Join(tmpDir, "k3s-data.tar.gz")
+
+	spinner := newSpinner("Archiving the k3s datastore...")
+	spinner.Start()
+	bundle.Manifest.Datastore, err = k3s.BackupData(mp, name, dataDir, bundle.DataPath)
+	if err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	if err := backup.Write(outputFile, bundle); err != nil {
+		os.Remove(outputFile)
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(backupResult{
+			Name:       name,
+			Datastore:  bundle.Manifest.Datastore,
+			K3sVersion: bundle.Manifest.K3sVersion,
+			File:       outputFile,
+		})
+	}
+
+	fmt.Printf("Backup of %s (%s datastore) saved to: %s\n", name, bundle.Manifest.Datastore, outputFile)
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// Actions reported for each cluster of a batch file
+const (
+	batchCreated = "created"
+	batchSkipped = "skipped"
+	batchFailed  = "failed"
+)
+
+// batchResult is the outcome of one cluster of a batch file
+type batchResult struct {
+	Name   string        `json:"name"`
+	Action string        `json:"action"`
+	Error  string        `json:"error,omitempty"`
+	Result *createResult `json:"result,omitempty"`
+}
+
+// createFromFile creates every cluster declared in path that does not exist yet.
+// Flags given on the command line apply to all clusters unless a cluster overrides them.
+func createFromFile(path string, base createOptions) error {
+	specs, err := config.LoadClusterSpecs(path)
+	if err != nil {
+		return err
+	}
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	var errs []error
+	results := make([]batchResult, 0, len(specs))
+	for _, spec := range specs {
+		name := multipass.ClusterVMName(spec.Name)
+		result := batchResult{Name: name}
+
+		_, err := mp.GetVMByName(name)
+		switch {
+		case err == nil:
+			result.Action = batchSkipped
+			infof("Cluster '%s' already exists, skipping.\n", name)
+		case !errors.Is(err, multipass.ErrVMNotFound):
+			result.Action = batchFailed
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("cluster '%s': %w", name, err))
+		default:
+			opts := base
+			opts.name = name
+			if spec.CPUs != 0 {
+				opts.cpus = spec.CPUs
+			}
+			if spec.Memory != "" {
+				opts.memory = spec.Memory
+			}
+			if spec.Disk != "" {
+				opts.disk = spec.Disk
+			}
+			if spec.Image != "" {
+				opts.image = spec.Image
+			}
+
+			created, err := createWithWorkers(mp, opts, spec.Workers)
+			if err != nil {
+				result.Action = batchFailed
+				result.Error = err.Error()
+				errs = append(errs, fmt.Errorf("cluster '%s': %w", name, err))
+			} else {
+				result.Action = batchCreated
+				result.Result = created
+			}
+		}
+
+		results = append(results, result)
+
+		// JSON Lines records are streamed as each cluster finishes
+		if jsonlOutput() {
+			if err := printJSON(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	if jsonOutput() {
+		if !jsonlOutput() {
+			if err := printJSON(results); err != nil {
+				return err
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tACTION\tDETAIL")
+	for _, result := range results {
+		detail := result.Error
+		if result.Result != nil {
+			detail = result.Result.IP
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, result.Action, detail)
+	}
+	w.Flush()
+
+	return errors.Join(errs...)
+}
+
+// createWithWorkers creates a cluster and joins the given number of workers to it,
+// each with the same resources as the server
+func createWithWorkers(mp *multipass.MultipassEnv, opts createOptions, workers int) (*createResult, error) {
+	created, _, err := provisionCluster(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if workers == 0 {
+		return created, nil
+	}
+
+	serverVM, err := mp.GetVMByName(created.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM details: %w", err)
+	}
+	serverMD, err := metadata.Load(created.Name)
+	if err != nil {
+		serverMD = nil
+	}
+
+	registryAuth, err := parseRegistryAuth(opts.registryAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Workers share the server's proxy and registry settings but none of its server flags
+	workerOpts := opts
+	workerOpts.name = ""
+	workerOpts.install = k3s.InstallOptions{
+		RegistryAuth:     registryAuth,
+		RegistryInsecure: opts.install.RegistryInsecure,
+		HTTPProxy:        opts.install.HTTPProxy,
+		HTTPSProxy:       opts.install.HTTPSProxy,
+		NoProxy:          opts.install.NoProxy,
+	}
+	for i := 0; i < workers; i++ {
+		if _, err := addWorker(mp, serverVM, serverMD, workerOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
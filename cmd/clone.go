@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewCloneCmd creates a command to create a cluster with the same spec as another
+func NewCloneCmd() *cobra.Command {
+	var wait bool
+
+	cloneCmd := &cobra.Command{
+		Use:   "clone <source> <destination>",
+		Short: "Create a new cluster with the same spec as an existing one",
+		Long: `Create a new cluster using the CPUs, memory, disk, image and k3s version
+recorded for an existing cluster. Only the provisioning spec is copied; workloads
+and data in the source cluster are not.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cloneCluster(args[0], args[1], wait)
+		},
+	}
+
+	cloneCmd.Flags().BoolVar(&wait, "wait", false, "Wait for the node to be Ready and the API server to be healthy")
+
+	return cloneCmd
+}
+
+// cloneCluster creates dst using the recorded provisioning spec of src
+func cloneCluster(src string, dst string, wait bool) error {
+	src = multipass.ClusterVMName(src)
+	dst = multipass.ClusterVMName(dst)
+
+	md, err := metadata.Load(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no metadata recorded for cluster '%s' (clusters created by older versions of mpkube cannot be cloned)", src)
+	}
+	if err != nil {
+		return err
+	}
+
+	// A worker's spec would create a standalone server, not a copy of its cluster
+	if md.IsAgent() {
+		server := md.Server
+		if server == "" {
+			server = md.ServerURL
+		}
+		return fmt.Errorf("'%s' is an agent of %s, not a cluster, and cannot be cloned", src, server)
+	}
+
+	infof("Cloning spec of '%s' into '%s'.\n", src, dst)
+	infoln("Note: only the provisioning spec is copied, not workloads or data.")
+
+	return createCluster(createOptions{
+		name:    dst,
+		cpus:    md.CPUs,
+		memory:  md.Memory,
+		disk:    md.Disk,
+		image:   md.Image,
+		wait:    wait,
+		timeout: defaultWaitTimeout,
+		install: k3s.InstallOptions{Version: md.K3sVersion, DataDir: md.DataDir},
+	})
+}
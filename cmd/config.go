@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCmd creates a command to manage the mpkube config file
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage mpkube defaults",
+		Long: `View and change defaults stored in ~/.mpkube/config.yaml.
+
+Supported keys:
+  ` + strings.Join(config.Keys(), "\n  "),
+	}
+
+	// Add subcommands
+	configCmd.AddCommand(NewConfigViewCmd())
+	configCmd.AddCommand(NewConfigGetCmd())
+	configCmd.AddCommand(NewConfigSetCmd())
+
+	return configCmd
+}
+
+// NewConfigViewCmd creates a command to print the config file
+func NewConfigViewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Print the current config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput() {
+				return printJSON(cfg)
+			}
+
+			data, err := cfg.YAML()
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		},
+	}
+}
+
+// NewConfigGetCmd creates a command to print a single config value
+func NewConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a config value",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(value)
+			return nil
+		},
+	}
+}
+
+// NewConfigSetCmd creates a command to change a config value
+func NewConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config value",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.Set(args[0], args[1]); err != nil {
+				return err
+			}
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			// Print the stored value, which may have been normalized
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s set to %s\n", args[0], value)
+			return nil
+		},
+	}
+}
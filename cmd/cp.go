@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewCpCmd creates a command to copy files between the host and a cluster VM
+func NewCpCmd() *cobra.Command {
+	cpCmd := &cobra.Command{
+		Use:   "cp <src> <dest>",
+		Short: "Copy files to or from a cluster",
+		Long: `Copy files between the host and a cluster VM using multipass transfer.
+
+Refer to a path inside a cluster as <mpkube-name>:<path>. Exactly one of the
+source and destination must be a cluster path.
+
+  mpkube cp ./app.yaml mydev:/home/ubuntu/app.yaml
+  mpkube cp mydev:/var/log/syslog ./syslog`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return copyFiles(args[0], args[1])
+		},
+	}
+
+	return cpCmd
+}
+
+// parseCopyArg splits a cp argument into a cluster name and path.
+// The cluster name is empty for local paths.
+func parseCopyArg(arg string) (string, string) {
+	idx := strings.Index(arg, ":")
+
+	// A single letter before the colon is a Windows drive, not a cluster
+	if idx <= 1 {
+		return "", arg
+	}
+
+	return arg[:idx], arg[idx+1:]
+}
+
+// copyFiles copies a file between the host and a cluster VM
+func copyFiles(src string, dest string) error {
+	srcCluster, srcPath := parseCopyArg(src)
+	destCluster, destPath := parseCopyArg(dest)
+
+	if (srcCluster == "") == (destCluster == "") {
+		return fmt.Errorf("exactly one of source and destination must be a cluster path (<mpkube-name>:<path>)")
+	}
+
+	// Only the local side is a host path; paths inside the VM are used as given
+	localPath := &srcPath
+	if srcCluster != "" {
+		localPath = &destPath
+	}
+	if err := expandPaths(localPath); err != nil {
+		return err
+	}
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	if srcCluster != "" {
+		// Add cluster prefix if not present
+		srcCluster = multipass.ClusterVMName(srcCluster)
+
+		if _, err := mp.GetVMByName(srcCluster); err != nil {
+			return fmt.Errorf("cluster '%s' not found: %w", srcCluster, err)
+		}
+
+		return mp.CopyFromVM(srcCluster, srcPath, destPath)
+	}
+
+	// Add cluster prefix if not present
+	destCluster = multipass.ClusterVMName(destCluster)
+
+	if _, err := mp.GetVMByName(destCluster); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", destCluster, err)
+	}
+
+	return mp.CopyToVM(srcPath, destCluster, destPath)
+}
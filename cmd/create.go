@@ -2,49 +2,511 @@ package cmd
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rodneyxr/mpkube/pkg/config"
 	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
 	"github.com/rodneyxr/mpkube/pkg/multipass"
 	"github.com/spf13/cobra"
 )
 
+// createResult is the machine-readable result of creating a cluster
+type createResult struct {
+	Name           string `json:"name"`
+	IP             string `json:"ip"`
+	KubeconfigPath string `json:"kubeconfig_path"`
+	K3sVersion     string `json:"k3s_version"`
+}
+
+// defaultWaitTimeout is how long create waits for the cluster when --wait is set
+const defaultWaitTimeout = 5 * time.Minute
+
+// createOptions holds the settings used to create a cluster
+type createOptions struct {
+	name    string
+	cpus    int
+	memory  string
+	disk    string
+	image   string
+	wait    bool
+	timeout time.Duration
+	install k3s.InstallOptions
+
+	// waitForManifests waits for the workloads in --manifest files to roll out
+	waitForManifests bool
+
+	// launchTimeout is passed to multipass launch as --timeout; zero keeps the multipass default
+	launchTimeout time.Duration
+
+	// nodeRole is labeled on the server node as node-role.kubernetes.io/<role>
+	nodeRole string
+
+	// serverURL and joinToken join the VM as an agent of a k3s server not managed by mpkube
+	serverURL string
+	joinToken string
+
+	writeKubeconfig    bool
+	validateKubeconfig bool
+
+	registryAuth []string
+	manifests    []string
+	labels       []string
+	networks     []string
+
+	postCreateScript string
+	rollback         bool
+
+	fromFile string
+}
+
 // NewCreateCmd creates a command to create a new k3s cluster
 func NewCreateCmd() *cobra.Command {
-	var cpus int
-	var memory string
-	var disk string
-	var name string
+	var opts createOptions
+	var cpus string
 
 	createCmd := &cobra.Command{
 		Use:   "create [name]",
 		Short: "Create a new k3s cluster",
-		Long:  `Create a new Kubernetes cluster using k3s in a Multipass VM with traefik disabled.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Create a new Kubernetes cluster using k3s in a Multipass VM with traefik disabled.
+
+Use --from-file to create a set of clusters declared in a YAML file. Clusters
+that already exist are skipped, so the file can be applied repeatedly:
+
+  clusters:
+    - name: dev
+      cpus: 4
+      memory: 4G
+    - name: test
+      image: 24.04
+
+Use --taint-server for a dedicated control plane. The server is tainted so that
+only critical add-ons run on it, which only makes sense once workers are added
+with 'mpkube join' (or 'workers:' in a --from-file batch); without workers, your
+own pods will stay Pending.
+
+Use --manifest to bootstrap workloads: each file, or the .yaml, .yml and .json
+files of each directory, is placed in the k3s manifests directory and applied
+automatically by k3s.
+
+Use --cluster-cidr and --service-cidr to move the pod and service networks away
+from ranges already in use on your network (k3s defaults to 10.42.0.0/16 and
+10.43.0.0/16). For a dual-stack cluster, pass an IPv4 and an IPv6 range separated
+by a comma to both, e.g. --cluster-cidr 10.42.0.0/16,2001:cafe:42::/56.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
-				name = args[0]
+				opts.name = args[0]
+			}
+
+			// Percentages and max are relative to this machine, not the one
+			// multipass runs on, so they would size a remote VM wrongly
+			if host := multipass.RemoteHost(); host != "" && config.IsRelativeCPUs(cpus) {
+				return fmt.Errorf("invalid --cpus: %q is relative to the local CPUs and cannot be used with remote host %s; pass a CPU count instead", cpus, host)
 			}
 
-			return createCluster(name, cpus, memory, disk)
+			var err error
+			if opts.cpus, err = config.ResolveCPUs(cpus, runtime.NumCPU()); err != nil {
+				return fmt.Errorf("invalid --cpus: %w", err)
+			}
+
+			paths := []*string{&opts.fromFile, &opts.postCreateScript, &opts.install.ResolvConfPath,
+				&opts.install.BinaryPath, &opts.install.ImagesPath, &opts.install.InstallScriptPath}
+			for i := range opts.manifests {
+				paths = append(paths, &opts.manifests[i])
+			}
+			if err := expandPaths(paths...); err != nil {
+				return err
+			}
+
+			if err := applyConfigDefaults(cmd, &opts); err != nil {
+				return err
+			}
+
+			if opts.fromFile != "" {
+				if opts.name != "" {
+					return fmt.Errorf("a cluster name cannot be combined with --from-file")
+				}
+				return createFromFile(opts.fromFile, opts)
+			}
+
+			return createCluster(opts)
 		},
 	}
 
 	// Add flags for customizing the VM
-	createCmd.Flags().IntVarP(&cpus, "cpus", "c", 2, "Number of CPUs for the VM")
-	createCmd.Flags().StringVarP(&memory, "memory", "m", "2G", "Memory allocation for the VM")
-	createCmd.Flags().StringVarP(&disk, "disk", "d", "10G", "Disk space for the VM")
-	createCmd.Flags().StringVar(&name, "name", "", "Name for the cluster (defaults to mpkube-<random> or mpkube-default if first cluster)")
+	createCmd.Flags().StringVarP(&cpus, "cpus", "c", "2", "Number of CPUs for the VM, a percentage of the host CPUs such as 50%, or max (only a number with --remote-host)")
+	createCmd.Flags().StringVarP(&opts.memory, "memory", "m", "2G", "Memory allocation for the VM, with a K, M or G unit (e.g. 512M or 4G)")
+	createCmd.Flags().StringVarP(&opts.disk, "disk", "d", "10G", "Disk space for the VM, with a K, M or G unit (e.g. 20G)")
+	createCmd.Flags().StringVarP(&opts.image, "image", "i", "22.04", "Multipass image or alias to launch (see 'multipass find')")
+	createCmd.Flags().StringVar(&opts.name, "name", "", "Name for the cluster (defaults to mpkube-<random> or mpkube-default if first cluster)")
+	createCmd.Flags().StringArrayVar(&opts.labels, "label", nil, "Label to record on the cluster as key=value, for list --selector (repeatable)")
+	createCmd.Flags().StringVar(&opts.fromFile, "from-file", "", "Create the clusters declared in a YAML file, skipping ones that already exist")
+	createCmd.Flags().BoolVar(&opts.wait, "wait", false, "Wait for the node to be Ready and the API server to be healthy")
+	createCmd.Flags().BoolVar(&opts.waitForManifests, "wait-for-manifests", false, "Wait for the Deployments, StatefulSets and DaemonSets in --manifest files to roll out")
+	createCmd.Flags().DurationVar(&opts.timeout, "timeout", defaultWaitTimeout, "Maximum time to wait when --wait or --wait-for-manifests is set")
+	createCmd.Flags().DurationVar(&opts.launchTimeout, "launch-timeout", 0, "Maximum time multipass waits for the VM to launch and cloud-init to finish, e.g. 5m (defaults to the multipass default)")
+	createCmd.Flags().StringArrayVar(&opts.networks, "network", nil, "Attach the VM to a multipass network, as a name or name=<network>[,mode=...][,mac=...] (repeatable, see 'mpkube networks')")
+
+	createCmd.Flags().StringVar(&opts.postCreateScript, "post-create-script", "", "Script to run as root inside the VM once the cluster is ready (KUBECONFIG is set); its output streams with --verbose")
+	createCmd.Flags().BoolVar(&opts.rollback, "rollback", false, "Delete the VM if --post-create-script fails")
+	createCmd.Flags().BoolVar(&opts.validateKubeconfig, "validate", false, "Check that the API server is reachable with the generated kubeconfig")
+	createCmd.Flags().BoolVar(&opts.writeKubeconfig, "write-kubeconfig", false, "Save the kubeconfig to kubeconfig-<name> in the kubeconfig.dir config directory (default ~/.kube/mpkube)")
+	createCmd.Flags().StringVar(&opts.install.Version, "k3s-version", "", "k3s version to install, e.g. v1.30.4+k3s1 (defaults to the latest stable release)")
+	createCmd.Flags().StringVar(&opts.install.Channel, "k3s-channel", "", "k3s release channel to install from: stable, latest, testing or a minor line such as v1.30")
+	createCmd.Flags().StringVar(&opts.install.DataDir, "data-dir", "", "Directory inside the VM where k3s keeps its state, e.g. a mounted fast disk (default "+k3s.DefaultDataDir+")")
+
+	// Flags for k3s networking
+	createCmd.Flags().StringVar(&opts.install.AdvertiseAddress, "advertise-address", "", "Address the API server advertises (defaults to the VM's IP)")
+	createCmd.Flags().StringVar(&opts.install.NodeIP, "node-ip", "", "Internal IP of the node (defaults to the VM's IP)")
+	createCmd.Flags().BoolVar(&opts.install.TaintServer, "taint-server", false, "Taint the server with CriticalAddonsOnly=true:NoExecute so workloads only run on workers")
+	createCmd.Flags().StringVar(&opts.install.ClusterCIDR, "cluster-cidr", "", "Pod network CIDR passed to k3s (e.g. 10.52.0.0/16)")
+	createCmd.Flags().StringVar(&opts.install.ServiceCIDR, "service-cidr", "", "Service network CIDR passed to k3s (e.g. 10.53.0.0/16)")
+
+	// Flags for node identity and labels
+	createCmd.Flags().StringVar(&opts.install.NodeName, "node-name", "", "Kubernetes node name (defaults to the VM name)")
+	createCmd.Flags().StringVar(&opts.nodeRole, "node-role", "", "Label the node with node-role.kubernetes.io/<role>=true so kubectl get nodes shows the role")
+
+	// Flags for DNS
+	createCmd.Flags().StringVar(&opts.install.ResolvConfPath, "resolv-conf", "", "resolv.conf whose nameservers CoreDNS forwards to (e.g. for split-horizon DNS)")
+
+	// Flags for joining an external k3s server
+	createCmd.Flags().StringVar(&opts.serverURL, "server-url", "", "Join the VM as an agent of this external k3s server, e.g. https://10.0.0.5:6443, instead of installing a server")
+	createCmd.Flags().StringVar(&opts.joinToken, "token", "", "Token to join the --server-url server with (its node-token)")
+
+	// Flags for bootstrapping workloads
+	createCmd.Flags().StringArrayVar(&opts.manifests, "manifest", nil, "Manifest file or directory for k3s to apply on startup (repeatable)")
+
+	// Flags for container registries
+	createCmd.Flags().StringArrayVar(&opts.registryAuth, "registry-auth", nil, "Private registry credentials as host=user:pass (repeatable)")
+	createCmd.Flags().StringArrayVar(&opts.install.RegistryInsecure, "registry-insecure", nil, "Registry host whose TLS certificate is not verified, e.g. a local dev registry (repeatable)")
+
+	// Flags for installing behind a proxy
+	createCmd.Flags().StringVar(&opts.install.HTTPProxy, "http-proxy", "", "HTTP proxy used for the k3s install and by containerd")
+	createCmd.Flags().StringVar(&opts.install.HTTPSProxy, "https-proxy", "", "HTTPS proxy used for the k3s install and by containerd")
+	createCmd.Flags().StringVar(&opts.install.NoProxy, "no-proxy", "", "Comma-separated hosts that bypass the proxy")
+
+	// Flags for installing k3s without internet access in the VM
+	createCmd.Flags().BoolVar(&opts.install.AirGapped, "air-gapped", false, "Install k3s from local artifacts instead of downloading them")
+	createCmd.Flags().StringVar(&opts.install.BinaryPath, "k3s-binary", "", "Path to the k3s binary (required with --air-gapped)")
+	createCmd.Flags().StringVar(&opts.install.ImagesPath, "k3s-images", "", "Path to the k3s airgap images tarball (used with --air-gapped)")
+	createCmd.Flags().StringVar(&opts.install.InstallScriptPath, "k3s-install-script", "", "Path to the k3s install script (required with --air-gapped)")
 
 	return createCmd
 }
 
-// createCluster creates a new k3s cluster in a Multipass VM
-func createCluster(name string, cpus int, memory string, disk string) error {
+// applyConfigDefaults fills in options from the config file for flags not set on the command line
+func applyConfigDefaults(cmd *cobra.Command, opts *createOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Flags().Changed("cpus") && cfg.Create.CPUs != 0 {
+		opts.cpus = cfg.Create.CPUs
+	}
+	if !cmd.Flags().Changed("memory") && cfg.Create.Memory != "" {
+		opts.memory = cfg.Create.Memory
+	}
+	if !cmd.Flags().Changed("disk") && cfg.Create.Disk != "" {
+		opts.disk = cfg.Create.Disk
+	}
+
+	return nil
+}
+
+// channelPattern matches the k3s release channels accepted by the installer
+var channelPattern = regexp.MustCompile(`^(stable|latest|testing|v[0-9]+\.[0-9]+)$`)
+
+// validateRelease checks that at most one of --k3s-version and --k3s-channel is set
+func validateRelease(install k3s.InstallOptions) error {
+	if install.Channel == "" {
+		return nil
+	}
+
+	if install.Version != "" {
+		return fmt.Errorf("--k3s-version and --k3s-channel cannot be used together")
+	}
+	if install.AirGapped {
+		return fmt.Errorf("--k3s-channel cannot be used with --air-gapped")
+	}
+	if !channelPattern.MatchString(install.Channel) {
+		return fmt.Errorf("invalid --k3s-channel %q: expected stable, latest, testing or a minor line such as v1.30", install.Channel)
+	}
+
+	return nil
+}
+
+// nodeNamePattern matches a DNS label (RFC 1123) as required for node names
+var nodeNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// validateNodeName checks that the node name override is a valid DNS label
+func validateNodeName(install k3s.InstallOptions) error {
+	if install.NodeName == "" {
+		return nil
+	}
+
+	if len(install.NodeName) > 63 || !nodeNamePattern.MatchString(install.NodeName) {
+		return fmt.Errorf("invalid --node-name %q: must be a DNS label of at most 63 lowercase letters, digits or '-', starting and ending with a letter or digit", install.NodeName)
+	}
+
+	return nil
+}
+
+// validateAirGapped checks that the local artifacts for an air-gapped install exist
+func validateAirGapped(install k3s.InstallOptions) error {
+	if !install.AirGapped {
+		if install.BinaryPath != "" || install.ImagesPath != "" || install.InstallScriptPath != "" {
+			return fmt.Errorf("--k3s-binary, --k3s-images and --k3s-install-script require --air-gapped")
+		}
+		return nil
+	}
+
+	if install.BinaryPath == "" {
+		return fmt.Errorf("--k3s-binary is required with --air-gapped")
+	}
+	if install.InstallScriptPath == "" {
+		return fmt.Errorf("--k3s-install-script is required with --air-gapped")
+	}
+
+	for _, path := range []string{install.BinaryPath, install.ImagesPath, install.InstallScriptPath} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("air-gapped artifact not accessible: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateAddresses checks that the advertise address and node IP are valid IPs
+func validateAddresses(install k3s.InstallOptions) error {
+	if install.AdvertiseAddress != "" && net.ParseIP(install.AdvertiseAddress) == nil {
+		return fmt.Errorf("invalid --advertise-address: %q is not an IP address", install.AdvertiseAddress)
+	}
+
+	// Dual-stack nodes take an IPv4 and an IPv6 address separated by a comma
+	if install.NodeIP != "" {
+		ips := strings.Split(install.NodeIP, ",")
+		if len(ips) > 2 {
+			return fmt.Errorf("invalid --node-ip: at most one IPv4 and one IPv6 address may be given")
+		}
+		for _, ip := range ips {
+			if net.ParseIP(ip) == nil {
+				return fmt.Errorf("invalid --node-ip: %q is not an IP address", ip)
+			}
+		}
+		if len(ips) == 2 && (net.ParseIP(ips[0]).To4() == nil) == (net.ParseIP(ips[1]).To4() == nil) {
+			return fmt.Errorf("invalid --node-ip: dual-stack addresses must be one IPv4 and one IPv6")
+		}
+	}
+
+	return nil
+}
+
+// validateCIDRs checks that the cluster and service CIDRs parse
+func validateCIDRs(install k3s.InstallOptions) error {
+	if err := validateCIDRList(install.ClusterCIDR); err != nil {
+		return fmt.Errorf("invalid --cluster-cidr: %w", err)
+	}
+
+	if err := validateCIDRList(install.ServiceCIDR); err != nil {
+		return fmt.Errorf("invalid --service-cidr: %w", err)
+	}
+
+	// k3s requires the service network to be dual-stack when the pod network is
+	if install.ServiceCIDR != "" && k3s.IsDualStack(install.ClusterCIDR) != k3s.IsDualStack(install.ServiceCIDR) {
+		return fmt.Errorf("--cluster-cidr and --service-cidr must both be single-stack or both be dual-stack")
+	}
+
+	return nil
+}
+
+// validateCIDRList checks a CIDR, or an IPv4 and an IPv6 CIDR separated by a comma for dual-stack
+func validateCIDRList(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	cidrs := strings.Split(value, ",")
+	if len(cidrs) > 2 {
+		return fmt.Errorf("at most one IPv4 and one IPv6 CIDR may be given")
+	}
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return err
+		}
+	}
+	if len(cidrs) == 2 && !k3s.IsDualStack(value) {
+		return fmt.Errorf("dual-stack CIDRs must be one IPv4 and one IPv6 range")
+	}
+
+	return nil
+}
+
+// parseRegistryAuth parses host=user:pass values into registry credentials
+func parseRegistryAuth(values []string) (map[string]k3s.RegistryAuth, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	auths := make(map[string]k3s.RegistryAuth, len(values))
+	for _, value := range values {
+		host, creds, ok := strings.Cut(value, "=")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid --registry-auth value: expected host=user:pass")
+		}
+
+		// Split on the first colon only so passwords may contain colons
+		user, pass, ok := strings.Cut(creds, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("invalid --registry-auth value for %s: expected host=user:pass", host)
+		}
+
+		auths[host] = k3s.RegistryAuth{Username: user, Password: pass}
+	}
+
+	return auths, nil
+}
+
+// labelKeyPattern matches label keys such as team or example.com/owner
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]*[A-Za-z0-9])?$`)
+
+// parseLabels parses key=value values into a label map
+func parseLabels(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", value)
+		}
+		if !labelKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid label key %q: must start and end with a letter or digit", key)
+		}
+		labels[key] = val
+	}
+
+	return labels, nil
+}
+
+// provisionCluster creates a new k3s cluster in a Multipass VM and returns the
+// result along with the cluster's kubeconfig
+func provisionCluster(opts createOptions) (*createResult, string, error) {
+	name := opts.name
+
+	if err := validateRelease(opts.install); err != nil {
+		return nil, "", err
+	}
+
+	if opts.launchTimeout < 0 {
+		return nil, "", fmt.Errorf("--launch-timeout must not be negative")
+	}
+
+	if opts.install.DataDir != "" {
+		if err := k3s.ValidateDataDir(opts.install.DataDir); err != nil {
+			return nil, "", fmt.Errorf("invalid --data-dir: %w", err)
+		}
+	}
+
+	if err := validateServerURL(opts); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateAirGapped(opts.install); err != nil {
+		return nil, "", err
+	}
+
+	if opts.install.ResolvConfPath != "" {
+		if _, err := os.Stat(opts.install.ResolvConfPath); err != nil {
+			return nil, "", fmt.Errorf("invalid --resolv-conf: %w", err)
+		}
+	}
+	if opts.postCreateScript != "" {
+		if _, err := os.Stat(opts.postCreateScript); err != nil {
+			return nil, "", fmt.Errorf("invalid --post-create-script: %w", err)
+		}
+	} else if opts.rollback {
+		return nil, "", fmt.Errorf("--rollback requires --post-create-script")
+	}
+
+	if err := validateCIDRs(opts.install); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateAddresses(opts.install); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateNodeName(opts.install); err != nil {
+		return nil, "", err
+	}
+
+	if opts.nodeRole != "" {
+		if err := k3s.ValidateNodeRole(opts.nodeRole); err != nil {
+			return nil, "", fmt.Errorf("invalid --node-role: %w", err)
+		}
+	}
+
+	memory, err := config.NormalizeSize(opts.memory)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --memory: %w", err)
+	}
+	opts.memory = memory
+
+	disk, err := config.NormalizeSize(opts.disk)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --disk: %w", err)
+	}
+	opts.disk = disk
+
+	registryAuth, err := parseRegistryAuth(opts.registryAuth)
+	if err != nil {
+		return nil, "", err
+	}
+	opts.install.RegistryAuth = registryAuth
+
+	manifests, err := k3s.ManifestFiles(opts.manifests)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var workloads []k3s.Workload
+	if opts.waitForManifests {
+		if len(manifests) == 0 {
+			return nil, "", fmt.Errorf("--wait-for-manifests requires --manifest")
+		}
+		if workloads, err = k3s.ManifestWorkloads(manifests); err != nil {
+			return nil, "", err
+		}
+		if len(workloads) == 0 {
+			fmt.Fprintln(os.Stderr, "Warning: no Deployments, StatefulSets or DaemonSets found in the manifests to wait for")
+		}
+	}
+
+	labels, err := parseLabels(opts.labels)
+	if err != nil {
+		return nil, "", err
+	}
+
 	mp, err := multipass.NewMultipassEnv()
 	if err != nil {
-		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+		return nil, "", fmt.Errorf("failed to initialize multipass environment: %w", err)
 	}
 
 	// Generate cluster name if not provided
@@ -52,71 +514,486 @@ func createCluster(name string, cpus int, memory string, disk string) error {
 		// Check if this is the first cluster
 		vms, err := mp.GetK3sVMs()
 		if err != nil {
-			return fmt.Errorf("failed to list VMs: %w", err)
+			return nil, "", fmt.Errorf("failed to list VMs: %w", err)
 		}
 
 		if len(vms) == 0 {
-			name = "mpkube-default"
+			name = multipass.ClusterPrefix() + "default"
 		} else {
 			// Generate random suffix (similar to k8s pod naming)
 			shortID := strings.Split(uuid.New().String(), "-")[0]
-			name = fmt.Sprintf("mpkube-%s", shortID)
+			name = multipass.ClusterPrefix() + shortID
+		}
+	}
+
+	// If name doesn't have the cluster prefix, add it
+	name = multipass.ClusterVMName(name)
+
+	// Catch a bad image before the launch fails deep inside multipass
+	if err := mp.ValidateImage(opts.image); err != nil {
+		return nil, "", err
+	}
+	for _, network := range opts.networks {
+		if err := mp.ValidateNetwork(network); err != nil {
+			return nil, "", err
+		}
+	}
+
+	warnHostCapacity(mp, opts)
+
+	infof("Creating k3s cluster with name: %s\n", name)
+
+	timings := newStageTimings(name)
+	defer timings.print()
+
+	if err := launchVM(mp, name, opts); err != nil {
+		return nil, "", err
+	}
+	timings.mark("launch")
+
+	// Record how the cluster was provisioned
+	md := &metadata.Cluster{
+		Name:      name,
+		CPUs:      opts.cpus,
+		Memory:    opts.memory,
+		Disk:      opts.disk,
+		Image:     opts.image,
+		CreatedAt: time.Now().UTC(),
+		Labels:    labels,
+		DataDir:   opts.install.DataDir,
+		ServerURL: opts.serverURL,
+	}
+	if err := metadata.Save(md); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Get the VM's IP address
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get VM details: %w", err)
+	}
+
+	infof("VM launched with IP: %s\n", vm.Address())
+	timings.mark("IP assignment")
+
+	if opts.serverURL != "" {
+		result, kubeconfig, err := joinExternalServer(mp, vm, md, opts)
+		timings.mark("k3s agent install")
+		return result, kubeconfig, err
+	}
+
+	// Install k3s on the VM
+	spinner := newSpinner("Installing k3s (this may take a few minutes)...")
+	spinner.Start()
+	if err := k3s.InstallK3s(mp, name, opts.install); err != nil {
+		spinner.Stop("failed")
+		return nil, "", fmt.Errorf("failed to install k3s: %w", err)
+	}
+	spinner.Stop("done")
+	timings.mark("k3s install")
+
+	infoln("K3s installed successfully!")
+
+	if opts.nodeRole != "" {
+		nodeName := opts.install.NodeName
+		if nodeName == "" {
+			nodeName = name
+		}
+		if err := k3s.LabelNodeRole(mp, name, nodeName, opts.nodeRole); err != nil {
+			return nil, "", err
+		}
+		timings.mark("node role label")
+	}
+
+	if len(manifests) > 0 {
+		spinner = newSpinner(fmt.Sprintf("Deploying %d manifest(s)...", len(manifests)))
+		spinner.Start()
+		if err := k3s.DeployManifests(mp, name, opts.install.DataDir, manifests); err != nil {
+			spinner.Stop("failed")
+			return nil, "", err
+		}
+		spinner.Stop("done")
+		timings.mark("manifests")
+	}
+
+	// Record the installed version so the cluster can be reproduced exactly
+	if version, err := k3s.GetVersion(mp, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		md.K3sVersion = version
+		if err := metadata.Save(md); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if opts.wait {
+		spinner = newSpinner("Waiting for the cluster to become ready...")
+		spinner.Start()
+		deadline := time.Now().Add(opts.timeout)
+
+		if err := k3s.WaitForReady(mp, name, opts.timeout); err != nil {
+			spinner.Stop("failed")
+			return nil, "", err
+		}
+		if err := k3s.WaitForAPIHealthy(mp, name, time.Until(deadline)); err != nil {
+			spinner.Stop("failed")
+			return nil, "", err
+		}
+		spinner.Stop("done")
+		timings.mark("ready wait")
+
+		infoln("Cluster is ready!")
+	}
+
+	if len(workloads) > 0 {
+		spinner = newSpinner(fmt.Sprintf("Waiting for %d workload(s) from manifests to roll out...", len(workloads)))
+		spinner.Start()
+		if err := k3s.WaitForWorkloads(mp, name, workloads, opts.timeout); err != nil {
+			spinner.Stop("failed")
+			return nil, "", err
+		}
+		spinner.Stop("done")
+		timings.mark("workload rollout")
+	}
+
+	if opts.postCreateScript != "" {
+		if err := runPostCreateScript(mp, name, opts); err != nil {
+			if opts.rollback {
+				infof("Rolling back: deleting %s...\n", name)
+				if delErr := mp.DeleteVM(name); delErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", delErr)
+				} else if mdErr := metadata.Delete(name); mdErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", mdErr)
+				}
+			}
+			return nil, "", err
+		}
+		timings.mark("post-create script")
+	}
+
+	// Get the kubeconfig
+	kubeconfig, err := k3s.GetKubeconfig(mp, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	timings.mark("kubeconfig")
+
+	kubeconfigPath := ""
+	if opts.writeKubeconfig {
+		kubeconfigPath, err = defaultKubeconfigPath(name)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := writeFileAtomic(kubeconfigPath, []byte(kubeconfig), 0600, false); err != nil {
+			return nil, "", fmt.Errorf("failed to write kubeconfig: %w", err)
+		}
+	}
+
+	if opts.validateKubeconfig {
+		if _, err := k3s.ValidateKubeconfig(kubeconfig); err != nil {
+			return nil, "", fmt.Errorf("kubeconfig validation failed (the cluster was created): %w", err)
 		}
+		infoln("Kubeconfig validated: the API server is reachable from this host.")
 	}
 
-	// If name doesn't have mpkube- prefix, add it
-	if !strings.HasPrefix(name, "mpkube-") {
-		name = fmt.Sprintf("mpkube-%s", name)
+	return &createResult{
+		Name:           name,
+		IP:             vm.Address(),
+		KubeconfigPath: kubeconfigPath,
+		K3sVersion:     md.K3sVersion,
+	}, kubeconfig, nil
+}
+
+// stageTimings records how long each stage of creating a cluster took, to show
+// where the time goes with --verbose
+type stageTimings struct {
+	name   string
+	start  time.Time
+	last   time.Time
+	stages []stageTiming
+}
+
+// stageTiming is the duration of one stage of a create
+type stageTiming struct {
+	stage    string
+	duration time.Duration
+}
+
+// newStageTimings starts timing the creation of the named cluster
+func newStageTimings(name string) *stageTimings {
+	now := time.Now()
+	return &stageTimings{name: name, start: now, last: now}
+}
+
+// mark records that stage finished, timing it from the end of the previous stage
+func (t *stageTimings) mark(stage string) {
+	now := time.Now()
+	t.stages = append(t.stages, stageTiming{stage: stage, duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// print writes the recorded stages and the total to stderr with --verbose
+func (t *stageTimings) print() {
+	if !verbose || len(t.stages) == 0 {
+		return
 	}
 
-	fmt.Printf("Creating k3s cluster with name: %s\n", name)
+	fmt.Fprintf(os.Stderr, "\nTimings for %s:\n", t.name)
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 3, ' ', 0)
+	for _, s := range t.stages {
+		fmt.Fprintf(w, "  %s\t%s\n", s.stage, s.duration.Round(100*time.Millisecond))
+	}
+	fmt.Fprintf(w, "  total\t%s\n", time.Since(t.start).Round(100*time.Millisecond))
+	w.Flush()
+}
+
+// joinExternalServer installs k3s in agent mode in vm and joins it to the
+// --server-url server. Agents have no admin kubeconfig, so none is returned.
+func joinExternalServer(mp *multipass.MultipassEnv, vm *multipass.VM, md *metadata.Cluster, opts createOptions) (*createResult, string, error) {
+	spinner := newSpinner(fmt.Sprintf("Joining k3s server %s...", opts.serverURL))
+	spinner.Start()
+	if err := k3s.InstallAgent(mp, vm.Name, opts.serverURL, opts.joinToken, opts.install); err != nil {
+		spinner.Stop("failed")
+		return nil, "", fmt.Errorf("failed to install k3s agent: %w", err)
+	}
+	spinner.Stop("done")
+
+	// Record the installed version so the agent can be reproduced exactly
+	if version, err := k3s.GetVersion(mp, vm.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		md.K3sVersion = version
+		if err := metadata.Save(md); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	return &createResult{
+		Name:       vm.Name,
+		IP:         vm.Address(),
+		K3sVersion: md.K3sVersion,
+	}, "", nil
+}
+
+// validateServerURL checks --server-url and --token, and that no option that
+// only applies to a k3s server is combined with joining an external one
+func validateServerURL(opts createOptions) error {
+	if opts.serverURL == "" {
+		if opts.joinToken != "" {
+			return fmt.Errorf("--token requires --server-url")
+		}
+		return nil
+	}
+	if opts.joinToken == "" {
+		return fmt.Errorf("--server-url requires --token")
+	}
+
+	u, err := url.Parse(opts.serverURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid --server-url %q (expected https://<host>:<port>)", opts.serverURL)
+	}
+
+	serverOnly := []struct {
+		flag string
+		set  bool
+	}{
+		{"--manifest", len(opts.manifests) > 0},
+		{"--wait", opts.wait},
+		{"--wait-for-manifests", opts.waitForManifests},
+		{"--post-create-script", opts.postCreateScript != ""},
+		{"--write-kubeconfig", opts.writeKubeconfig},
+		{"--validate", opts.validateKubeconfig},
+		{"--air-gapped", opts.install.AirGapped},
+		{"--advertise-address", opts.install.AdvertiseAddress != ""},
+		{"--taint-server", opts.install.TaintServer},
+		{"--cluster-cidr", opts.install.ClusterCIDR != ""},
+		{"--service-cidr", opts.install.ServiceCIDR != ""},
+		{"--resolv-conf", opts.install.ResolvConfPath != ""},
+		{"--data-dir", opts.install.DataDir != ""},
+		{"--node-role", opts.nodeRole != ""},
+	}
+	for _, option := range serverOnly {
+		if option.set {
+			return fmt.Errorf("%s cannot be used with --server-url", option.flag)
+		}
+	}
+
+	return nil
+}
+
+// runPostCreateScript waits for the cluster to be ready, unless --wait already
+// did, and runs the --post-create-script inside the VM
+func runPostCreateScript(mp *multipass.MultipassEnv, name string, opts createOptions) error {
+	if !opts.wait {
+		spinner := newSpinner("Waiting for the cluster to become ready...")
+		spinner.Start()
+		if err := k3s.WaitForReady(mp, name, opts.timeout); err != nil {
+			spinner.Stop("failed")
+			return err
+		}
+		spinner.Stop("done")
+	}
+
+	// Stream the script's output with --verbose, keeping stdout clean for JSON output
+	if verbose {
+		infof("Running post-create script %s...\n", opts.postCreateScript)
+		return k3s.RunScript(mp, name, opts.postCreateScript, os.Stderr)
+	}
+
+	spinner := newSpinner(fmt.Sprintf("Running post-create script %s...", opts.postCreateScript))
+	spinner.Start()
+	if err := k3s.RunScript(mp, name, opts.postCreateScript, nil); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+	return nil
+}
 
-	// Launch the VM
+// launchVM launches the multipass VM for a node with the resources in opts
+func launchVM(mp *multipass.MultipassEnv, name string, opts createOptions) error {
 	launchArgs := []string{
 		"launch",
 		"--name", name,
-		"--cpus", fmt.Sprintf("%d", cpus),
-		"--memory", memory,
-		"--disk", disk,
+		"--cpus", fmt.Sprintf("%d", opts.cpus),
+		"--memory", opts.memory,
+		"--disk", opts.disk,
+	}
+	for _, network := range opts.networks {
+		launchArgs = append(launchArgs, "--network", network)
+	}
+	if opts.launchTimeout > 0 {
+		// multipass takes whole seconds, so round up rather than pass 0
+		seconds := int(math.Ceil(opts.launchTimeout.Seconds()))
+		launchArgs = append(launchArgs, "--timeout", fmt.Sprintf("%d", seconds))
 	}
 
-	// For simplicity, use ubuntu 22.04 LTS
-	launchArgs = append(launchArgs, "22.04")
+	launchArgs = append(launchArgs, opts.image)
 
-	fmt.Println("Launching Multipass VM...")
+	// multipass's own progress is buffered rather than echoed next to the
+	// spinner; it is only shown when the launch fails, or with --verbose
+	spinner := newSpinner("Launching Multipass VM...")
+	spinner.Start()
 	output, err := mp.RunMultipassCmd(launchArgs...)
 	if err != nil {
-		return fmt.Errorf("failed to launch VM: %w\n%s", err, output)
+		spinner.Stop("failed")
+		if resource := multipass.ExhaustedResource(output); resource != "" {
+			return resourceError(mp, name, resource, opts, output)
+		}
+		return fmt.Errorf("failed to launch VM: %w\n%s", err, launchOutput(output))
 	}
+	spinner.Stop("done")
 
-	// Get the VM's IP address
-	vm, err := mp.GetVMByName(name)
+	if verbose {
+		fmt.Fprintln(os.Stderr, launchOutput(output))
+	}
+
+	return nil
+}
+
+// launchOutput strips the spinner frames multipass redraws with carriage
+// returns from its launch output, keeping the last state of each line
+func launchOutput(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(slices.DeleteFunc(lines, func(line string) bool { return line == "" }), "\n")
+}
+
+// hostMemoryHeadroom is the fraction of host memory below which running VMs
+// are considered to nearly exhaust it
+const hostMemoryHeadroom = 0.9
+
+// warnHostCapacity warns, without failing, when the VM would exceed or nearly
+// exhaust the host's memory or CPUs given what running VMs already use
+func warnHostCapacity(mp *multipass.MultipassEnv, opts createOptions) {
+	if mp.RemoteHost != "" {
+		return
+	}
+
+	// The check is advisory, so it is skipped quietly when usage is unknown
+	running, err := mp.RunningUsage()
 	if err != nil {
-		return fmt.Errorf("failed to get VM details: %w", err)
+		return
 	}
 
-	fmt.Printf("VM launched with IP: %s\n", vm.IPv4)
-	fmt.Println("Installing k3s (this may take a few minutes)...")
+	if requested, err := config.SizeBytes(opts.memory); err == nil {
+		if total, err := mp.HostMemory(); err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Skipping the host memory check: %v\n", err)
+			}
+		} else if free := total - running.Memory; requested > free {
+			fmt.Fprintf(os.Stderr, "Warning: requesting %s of memory but only %s free across host (%d running VM(s) use %s of %s)\n",
+				formatBytes(requested), formatBytes(max(free, 0)), running.Running, formatBytes(running.Memory), formatBytes(total))
+		} else if float64(running.Memory+requested) > hostMemoryHeadroom*float64(total) {
+			fmt.Fprintf(os.Stderr, "Warning: requesting %s of memory leaves only %s of the host's %s for the host itself\n",
+				formatBytes(requested), formatBytes(free-requested), formatBytes(total))
+		}
+	}
 
-	// Install k3s on the VM
-	if err := k3s.InstallK3s(mp, name); err != nil {
-		return fmt.Errorf("failed to install k3s: %w", err)
+	if hostCPUs := runtime.NumCPU(); running.CPUs+opts.cpus > hostCPUs {
+		fmt.Fprintf(os.Stderr, "Warning: requesting %d CPU(s) while running VMs already use %d of the host's %d; VMs will compete for CPU time\n",
+			opts.cpus, running.CPUs, hostCPUs)
+	}
+}
+
+// resourceError explains a launch that failed because the host ran out of
+// memory or disk, comparing the requested size with what running VMs use
+func resourceError(mp *multipass.MultipassEnv, name string, resource string, opts createOptions, output string) error {
+	requested, flag := opts.memory, "--memory"
+	if resource == multipass.ResourceDisk {
+		requested, flag = opts.disk, "--disk"
 	}
 
-	fmt.Println("K3s installed successfully!")
+	usage := ""
+	if running, err := mp.RunningUsage(); err == nil && running.Running > 0 {
+		used := running.Memory
+		if resource == multipass.ResourceDisk {
+			used = running.Disk
+		}
+		usage = fmt.Sprintf("; %d running VM(s) already use %s", running.Running, formatBytes(used))
+	}
 
-	// Get the kubeconfig
-	kubeconfig, err := k3s.GetKubeconfig(mp, name)
+	return fmt.Errorf("%w: the host does not have enough %s to launch %s (requested %s %s)%s. Stop or delete other VMs (see 'mpkube list --all') or lower %s\n%s",
+		multipass.ErrInsufficientResources, resource, name, flag, requested, usage, flag, strings.TrimSpace(output))
+}
+
+// createCluster creates a new k3s cluster in a Multipass VM and prints how to access it
+func createCluster(opts createOptions) error {
+	result, kubeconfig, err := provisionCluster(opts)
 	if err != nil {
-		return fmt.Errorf("failed to get kubeconfig: %w", err)
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(result)
+	}
+
+	if opts.serverURL != "" {
+		fmt.Printf("\nAgent %s (IP: %s) joined k3s server %s.\n", result.Name, result.IP, opts.serverURL)
+		return nil
 	}
 
 	fmt.Println("\nCluster created successfully!")
-	fmt.Printf("Cluster name: %s\n", name)
-	fmt.Printf("Cluster IP: %s\n", vm.IPv4)
+	fmt.Printf("Cluster name: %s\n", result.Name)
+	fmt.Printf("Cluster IP: %s\n", result.IP)
+	warnRemoteKubeconfig(result.Name)
+
+	if result.KubeconfigPath != "" {
+		fmt.Printf("Kubeconfig saved to: %s\n", result.KubeconfigPath)
+		fmt.Println("\nUse the following command to access the cluster:")
+		fmt.Printf("export KUBECONFIG=%s\n", result.KubeconfigPath)
+		return nil
+	}
+
 	fmt.Println("\nUse the following command to access the cluster:")
 	fmt.Printf("export KUBECONFIG=<path/to/save/config>\n")
-	fmt.Printf("mpkube kubeconfig get %s -o $KUBECONFIG\n", name)
+	fmt.Printf("mpkube kubeconfig get %s -f $KUBECONFIG\n", result.Name)
 	fmt.Println("\nOr use the kubeconfig directly:")
 	fmt.Println(kubeconfig)
 
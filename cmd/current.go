@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// currentReport describes the cluster the current kubeconfig context points at
+type currentReport struct {
+	Context string `json:"context"`
+	Managed bool   `json:"managed"`
+	Cluster string `json:"cluster,omitempty"`
+	IP      string `json:"ip,omitempty"`
+	State   string `json:"state,omitempty"`
+}
+
+// NewCurrentCmd creates a command to show which cluster the current context points at
+func NewCurrentCmd() *cobra.Command {
+	var kubeconfigPath string
+
+	currentCmd := &cobra.Command{
+		Use:     "current",
+		Aliases: []string{"whoami"},
+		Short:   "Show the mpkube cluster of the current kubeconfig context",
+		Long: `Read the current context of a kubeconfig and, if it belongs to an mpkube
+cluster, show the cluster name, IP and live state of its VM.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := expandPaths(&kubeconfigPath); err != nil {
+				return err
+			}
+			return showCurrent(kubeconfigPath)
+		},
+	}
+
+	currentCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", clientcmd.RecommendedHomeFile, "Path to the kubeconfig to read")
+
+	return currentCmd
+}
+
+// showCurrent resolves the current kubeconfig context to an mpkube cluster
+func showCurrent(kubeconfigPath string) error {
+	config, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if config.CurrentContext == "" {
+		return fmt.Errorf("%s has no current context", kubeconfigPath)
+	}
+
+	report := currentReport{Context: config.CurrentContext}
+
+	// mpkube names the context, cluster and user after the cluster VM
+	cluster := config.CurrentContext
+	if context, ok := config.Contexts[config.CurrentContext]; ok && strings.HasPrefix(context.Cluster, multipass.ClusterPrefix()) {
+		cluster = context.Cluster
+	}
+	report.Managed = strings.HasPrefix(cluster, multipass.ClusterPrefix())
+
+	if report.Managed {
+		report.Cluster = cluster
+
+		mp, err := multipass.NewMultipassEnv()
+		if err != nil {
+			return fmt.Errorf("failed to initialize multipass environment: %w", err)
+		}
+
+		vm, err := mp.GetVMByName(cluster)
+		switch {
+		case errors.Is(err, multipass.ErrVMNotFound):
+			report.State = "Deleted"
+		case err != nil:
+			return err
+		default:
+			report.IP = vm.Address()
+			report.State = vm.State
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	if !report.Managed {
+		fmt.Printf("Current context '%s' is not an mpkube cluster.\n", report.Context)
+		return nil
+	}
+
+	fmt.Printf("Context: %s\n", report.Context)
+	fmt.Printf("Cluster: %s\n", report.Cluster)
+	if report.State == "Deleted" {
+		fmt.Println("State:   Deleted (run 'mpkube kubeconfig purge' to remove stale contexts)")
+		return nil
+	}
+	fmt.Printf("IP:      %s\n", report.IP)
+	fmt.Printf("State:   %s\n", report.State)
+	return nil
+}
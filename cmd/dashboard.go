@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewDashboardCmd creates a command to deploy and open the Kubernetes dashboard
+func NewDashboardCmd() *cobra.Command {
+	var port int
+
+	dashboardCmd := &cobra.Command{
+		Use:   "dashboard <name>",
+		Short: "Deploy and open the Kubernetes dashboard",
+		Long: `Deploy the Kubernetes dashboard to a cluster, create an admin login token,
+and port-forward the dashboard on the cluster IP until interrupted.
+
+Re-running the command against a cluster that already has the dashboard is safe.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return openDashboard(args[0], port)
+		},
+	}
+
+	dashboardCmd.Flags().IntVarP(&port, "port", "p", 8443, "Port on the cluster IP to serve the dashboard on")
+
+	return dashboardCmd
+}
+
+// openDashboard installs the dashboard and forwards it until interrupted
+func openDashboard(name string, port int) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+	address := vm.Address()
+	if address == "" {
+		return fmt.Errorf("cluster '%s' has no IP address yet (state: %s)", name, vm.State)
+	}
+
+	fmt.Println("Installing Kubernetes dashboard...")
+	if err := k3s.InstallDashboard(mp, name); err != nil {
+		return err
+	}
+
+	token, err := k3s.DashboardToken(mp, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nDashboard URL: https://%s/\n", net.JoinHostPort(address, strconv.Itoa(port)))
+	fmt.Println("Log in with the following token:")
+	fmt.Println(token)
+	fmt.Println("\nPress Ctrl-C to stop the dashboard port-forward.")
+
+	// Listen only on the cluster IP, so the admin-token dashboard is reachable
+	// from the host but not on other interfaces such as a bridged --network
+	return k3s.KubectlInteractive(mp, name,
+		"-n", k3s.DashboardNamespace,
+		"port-forward", "--address", address,
+		"svc/kubernetes-dashboard", fmt.Sprintf("%d:443", port),
+	)
+}
@@ -1,77 +1,177 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/rodneyxr/mpkube/pkg/metadata"
 	"github.com/rodneyxr/mpkube/pkg/multipass"
 	"github.com/spf13/cobra"
 )
 
-// NewDeleteCmd creates a command to delete a k3s cluster
+// NewDeleteCmd creates a command to delete k3s clusters
 func NewDeleteCmd() *cobra.Command {
 	var force bool
+	var all bool
+	var purgeVolumes bool
 
 	deleteCmd := &cobra.Command{
-		Use:   "delete [name]",
-		Short: "Delete a k3s cluster",
-		Long:  `Delete a Kubernetes cluster by removing the underlying Multipass VM.`,
-		Args:  cobra.ExactArgs(1),
+		Use:   "delete [name...]",
+		Short: "Delete one or more k3s clusters",
+		Long: `Delete Kubernetes clusters by removing the underlying Multipass VMs.
+
+With -o json, the names of the deleted clusters and any errors are printed as
+{"deleted": [...], "errors": [...]}. JSON output never prompts, so --force or
+--assume-yes is required.`,
+		Args: namesOrAll(&all),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
-			return deleteCluster(name, force)
+			return deleteClusters(args, all, force, purgeVolumes)
 		},
 	}
 
 	// Add flags
 	deleteCmd.Flags().BoolVarP(&force, "force", "f", false, "Force deletion without confirmation")
+	deleteCmd.Flags().BoolVar(&all, "all", false, "Delete all mpkube clusters")
+	deleteCmd.Flags().BoolVar(&purgeVolumes, "purge-volumes", false, "Run 'multipass purge' afterwards to reclaim disk from any previously deleted VMs")
 
 	return deleteCmd
 }
 
-// deleteCluster deletes a k3s cluster by removing the Multipass VM
-func deleteCluster(name string, force bool) error {
+// deleteReport is the machine-readable result of a delete
+type deleteReport struct {
+	Deleted []string `json:"deleted"`
+	Errors  []string `json:"errors"`
+}
+
+// printDeleteReport prints the deleted clusters and errors as JSON
+func printDeleteReport(deleted []string, errs []error) error {
+	report := deleteReport{Deleted: nonNil(deleted), Errors: []string{}}
+	for _, err := range errs {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	return printJSON(report)
+}
+
+// deleteClusters deletes k3s clusters by removing their Multipass VMs.
+// A failure for one cluster does not stop the others from being deleted.
+func deleteClusters(names []string, all bool, force bool, purgeVolumes bool) error {
 	mp, err := multipass.NewMultipassEnv()
 	if err != nil {
 		return fmt.Errorf("failed to initialize multipass environment: %w", err)
 	}
 
-	// If name doesn't have mpkube- prefix, add it
-	if !strings.HasPrefix(name, "mpkube-") {
-		name = fmt.Sprintf("mpkube-%s", name)
+	var errs []error
+	var targets []multipass.VM
+
+	if all {
+		targets, err = clusterVMs(mp)
+		if err != nil {
+			return fmt.Errorf("failed to list clusters: %w", err)
+		}
+
+		if len(targets) == 0 {
+			if jsonOutput() {
+				return printDeleteReport(nil, nil)
+			}
+			fmt.Println("No K3s clusters found.")
+			return nil
+		}
+	} else {
+		for _, name := range names {
+			// If name doesn't have the cluster prefix, add it
+			name = multipass.ClusterVMName(name)
+
+			// Check if the VM exists
+			vm, err := mp.GetVMByName(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cluster '%s' not found: %w", name, err))
+				continue
+			}
+			targets = append(targets, *vm)
+		}
+
+		if len(targets) == 0 {
+			if jsonOutput() {
+				if err := printDeleteReport(nil, errs); err != nil {
+					return err
+				}
+			}
+			return errors.Join(errs...)
+		}
+	}
+
+	// Workers cannot run without their server, so they are deleted with it
+	targets, workerOf := withWorkers(mp, targets)
+
+	// Confirmation unless --force or --assume-yes is used
+	var prompt strings.Builder
+	prompt.WriteString("The following clusters will be deleted:\n")
+	for _, vm := range targets {
+		if server, ok := workerOf[vm.Name]; ok {
+			fmt.Fprintf(&prompt, "  %s (IP: %s, worker of %s)\n", vm.Name, vm.Address(), server)
+			continue
+		}
+		fmt.Fprintf(&prompt, "  %s (IP: %s)\n", vm.Name, vm.Address())
 	}
+	prompt.WriteString("Are you sure? [y/N]: ")
 
-	// Check if the VM exists
-	vm, err := mp.GetVMByName(name)
+	ok, err := Confirm(prompt.String(), force || assumeYes)
+	if errors.Is(err, errNotInteractive) {
+		return fmt.Errorf("%w; pass --force or --assume-yes to delete without confirmation", err)
+	}
 	if err != nil {
-		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+		return err
+	}
+	if !ok {
+		fmt.Println("Deletion cancelled.")
+		return nil
 	}
 
-	// Confirmation unless force flag is used
-	if !force {
-		fmt.Printf("Are you sure you want to delete cluster '%s' (IP: %s)? [y/N]: ", vm.Name, vm.IPv4)
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+	var deleted []string
+	for _, vm := range targets {
+		infof("Deleting cluster '%s'...\n", vm.Name)
+
+		// Delete the VM
+		if err := mp.DeleteVM(vm.Name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete cluster '%s': %w", vm.Name, err))
+			continue
 		}
 
-		input = strings.TrimSpace(strings.ToLower(input))
-		if input != "y" && input != "yes" {
-			fmt.Println("Deletion cancelled.")
-			return nil
+		if err := metadata.Delete(vm.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 		}
+
+		deleted = append(deleted, vm.Name)
+		infof("Cluster '%s' deleted successfully.\n", vm.Name)
 	}
 
-	fmt.Printf("Deleting cluster '%s'...\n", name)
+	if purgeVolumes {
+		infoln("Purging deleted VMs...")
+		if err := mp.Purge(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if jsonOutput() {
+		if err := printDeleteReport(deleted, errs); err != nil {
+			return err
+		}
+		return errors.Join(errs...)
+	}
+
+	// Summarize when more than one cluster was involved
+	if len(names) > 1 || all {
+		fmt.Printf("\nDeleted %d cluster(s).\n", len(deleted))
+		for _, err := range errs {
+			fmt.Printf("  FAILED: %v\n", err)
+		}
+	}
 
-	// Delete the VM
-	if err := mp.DeleteVM(name); err != nil {
-		return fmt.Errorf("failed to delete cluster: %w", err)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 
-	fmt.Printf("Cluster '%s' deleted successfully.\n", name)
 	return nil
 }
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// clusterDescription is everything mpkube knows about a cluster
+type clusterDescription struct {
+	statusReport
+	ServerURL  string            `json:"server_url"`
+	Release    string            `json:"release,omitempty"`
+	CPUs       int               `json:"cpus,omitempty"`
+	Memory     resourceUsage     `json:"memory"`
+	Disk       resourceUsage     `json:"disk"`
+	Mounts     []multipass.Mount `json:"mounts"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	CreatedAt  *time.Time        `json:"created_at,omitempty"`
+	DataDir    string            `json:"data_dir,omitempty"`
+	JoinedTo   string            `json:"joined_to,omitempty"`
+	Workers    []string          `json:"workers,omitempty"`
+	Kubeconfig string            `json:"kubeconfig_path,omitempty"`
+}
+
+// resourceUsage is the size and usage of a VM resource in bytes; zero when unknown
+type resourceUsage struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+}
+
+// String renders the usage as "used / total", or "-" when unknown
+func (r resourceUsage) String() string {
+	if r.Total == 0 {
+		return "-"
+	}
+	if r.Used == 0 {
+		return formatBytes(r.Total)
+	}
+	return formatBytes(r.Used) + " / " + formatBytes(r.Total)
+}
+
+// NewDescribeCmd creates a command to show everything about a cluster
+func NewDescribeCmd() *cobra.Command {
+	describeCmd := &cobra.Command{
+		Use:   "describe <name>",
+		Short: "Show everything about a cluster",
+		Long: `Show the VM resources and mounts of a cluster, the health of k3s and its
+nodes, the API server URL, and the labels and other details recorded when it
+was created, in a single report. Use -o json or -o yaml for a machine-readable
+version.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return describeCluster(args[0])
+		},
+	}
+
+	return describeCmd
+}
+
+// describeCluster collects and prints the description of a cluster
+func describeCluster(name string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	desc := clusterDescription{
+		statusReport: clusterStatus(mp, vm),
+		ServerURL:    k3s.ServerURL(vm),
+		Mounts:       []multipass.Mount{},
+	}
+
+	if info, err := mp.GetVMInfo(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		desc.Release = info.Release
+		desc.CPUs = info.CPUs
+		desc.Memory = resourceUsage{Total: info.MemoryTotal, Used: info.MemoryUsed}
+		desc.Disk = resourceUsage{Total: info.DiskTotal, Used: info.DiskUsed}
+		desc.Mounts = info.Mounts
+	}
+
+	md, err := metadata.Load(name)
+	switch {
+	case err == nil:
+		desc.Labels = md.Labels
+		if !md.CreatedAt.IsZero() {
+			desc.CreatedAt = &md.CreatedAt
+		}
+		desc.DataDir = md.DataDir
+		desc.JoinedTo = md.Server
+		if md.ServerURL != "" {
+			// The agent talks to the external server rather than serving an API itself
+			desc.JoinedTo = md.ServerURL
+			desc.ServerURL = md.ServerURL
+		}
+		desc.Workers = md.Workers
+		if desc.Release == "" {
+			desc.Release = md.Image
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if path, err := defaultKubeconfigPath(name); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			desc.Kubeconfig = path
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(desc)
+	}
+
+	printDescription(desc)
+	return nil
+}
+
+// printDescription prints the description as a human-readable report
+func printDescription(desc clusterDescription) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", desc.Name)
+	fmt.Fprintf(w, "State:\t%s\n", desc.State)
+	fmt.Fprintf(w, "IP:\t%s\n", desc.IP)
+	fmt.Fprintf(w, "API server:\t%s\n", desc.ServerURL)
+	if desc.Kubeconfig != "" {
+		fmt.Fprintf(w, "Kubeconfig:\t%s\n", desc.Kubeconfig)
+	}
+	fmt.Fprintf(w, "Release:\t%s\n", valueOrDash(desc.Release))
+	if desc.CPUs > 0 {
+		fmt.Fprintf(w, "CPUs:\t%d\n", desc.CPUs)
+	} else {
+		fmt.Fprintf(w, "CPUs:\t-\n")
+	}
+	fmt.Fprintf(w, "Memory:\t%s\n", desc.Memory)
+	fmt.Fprintf(w, "Disk:\t%s\n", desc.Disk)
+	if desc.DataDir != "" {
+		fmt.Fprintf(w, "k3s data dir:\t%s\n", desc.DataDir)
+	}
+	fmt.Fprintf(w, "k3s active:\t%t\n", desc.K3sActive)
+	fmt.Fprintf(w, "k3s version:\t%s\n", valueOrDash(desc.Version))
+	if desc.CreatedAt != nil {
+		fmt.Fprintf(w, "Created:\t%s\n", desc.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if desc.JoinedTo != "" {
+		fmt.Fprintf(w, "Worker of:\t%s\n", desc.JoinedTo)
+	}
+	if len(desc.Workers) > 0 {
+		fmt.Fprintf(w, "Workers:\t%s\n", strings.Join(desc.Workers, ", "))
+	}
+	fmt.Fprintf(w, "Labels:\t%s\n", valueOrDash(formatLabels(desc.Labels)))
+	w.Flush()
+
+	if len(desc.Mounts) > 0 {
+		fmt.Println("\nMounts:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tTARGET")
+		for _, mount := range desc.Mounts {
+			fmt.Fprintf(w, "%s\t%s\n", mount.SourcePath, mount.TargetPath)
+		}
+		w.Flush()
+	}
+
+	printNodeTable(desc.Nodes)
+}
+
+// formatLabels renders labels as sorted key=value pairs separated by commas
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// valueOrDash returns value, or "-" if it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
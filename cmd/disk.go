@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewDiskCmd creates a command to manage the disk of a cluster VM
+func NewDiskCmd() *cobra.Command {
+	diskCmd := &cobra.Command{
+		Use:   "disk",
+		Short: "Manage the disk of a cluster VM",
+	}
+
+	diskCmd.AddCommand(newDiskGrowCmd())
+
+	return diskCmd
+}
+
+// newDiskGrowCmd creates a command to grow the disk of a cluster VM
+func newDiskGrowCmd() *cobra.Command {
+	var timeout time.Duration
+
+	growCmd := &cobra.Command{
+		Use:   "grow <name> <size>",
+		Short: "Grow the disk of a cluster VM without recreating it",
+		Long: `Grow the disk of a cluster VM to the given size, e.g. 40G. The size needs a
+K, M or G unit.
+
+Multipass can only resize the disk of a stopped VM, so a running cluster is
+stopped, resized and started again. Disks cannot be shrunk.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return growDisk(args[0], args[1], timeout)
+		},
+	}
+
+	growCmd.Flags().DurationVar(&timeout, "timeout", defaultWaitTimeout, "Maximum time to wait for the VM to stop and start again")
+
+	return growCmd
+}
+
+// growDisk resizes the disk of a cluster VM to size, stopping the VM if needed
+func growDisk(name string, size string, timeout time.Duration) error {
+	size, err := config.NormalizeSize(size)
+	if err != nil {
+		return err
+	}
+	requested, err := config.SizeBytes(size)
+	if err != nil {
+		return err
+	}
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	current, err := mp.DiskSize(name)
+	if err != nil {
+		return err
+	}
+	if requested <= current {
+		return fmt.Errorf("cannot resize disk of %s to %s: disks can only grow (current size is %s)", name, size, formatBytes(current))
+	}
+
+	deadline := time.Now().Add(timeout)
+	running := vm.State == "Running"
+
+	var before *multipass.VMInfo
+	started := false
+	if running {
+		if before, err = mp.GetVMInfo(name); err != nil {
+			return err
+		}
+
+		spinner := newSpinner(fmt.Sprintf("Stopping %s...", name))
+		spinner.Start()
+		if err := mp.StopVM(name); err != nil {
+			spinner.Stop("failed")
+			return err
+		}
+
+		// Bring the cluster back up if resizing or starting fails, rather than
+		// leaving it down
+		defer func() {
+			if started {
+				return
+			}
+			spinner := newSpinner(fmt.Sprintf("Restarting %s after the failed resize...", name))
+			spinner.Start()
+			if err := mp.StartVM(name); err != nil {
+				spinner.Stop("failed")
+				fmt.Fprintf(os.Stderr, "Warning: failed to restart %s: %v\n", name, err)
+				return
+			}
+			spinner.Stop("done")
+		}()
+
+		if err := mp.WaitForState(name, "Stopped", time.Until(deadline)); err != nil {
+			spinner.Stop("failed")
+			return err
+		}
+		spinner.Stop("done")
+	}
+
+	spinner := newSpinner(fmt.Sprintf("Resizing disk to %s...", size))
+	spinner.Start()
+	if err := mp.SetDiskSize(name, size); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	if running {
+		spinner = newSpinner(fmt.Sprintf("Starting %s...", name))
+		spinner.Start()
+		if err := mp.StartVM(name); err != nil {
+			spinner.Stop("failed")
+			return err
+		}
+		if err := mp.WaitForState(name, "Running", time.Until(deadline)); err != nil {
+			spinner.Stop("failed")
+			return err
+		}
+		spinner.Stop("done")
+		started = true
+	}
+
+	// Verify the new size was applied
+	resized, err := mp.DiskSize(name)
+	if err != nil {
+		return err
+	}
+	if resized <= current {
+		return fmt.Errorf("disk of %s was not resized: size is still %s", name, formatBytes(resized))
+	}
+	if running {
+		after, err := mp.GetVMInfo(name)
+		if err != nil {
+			return err
+		}
+		if after.DiskTotal <= before.DiskTotal {
+			fmt.Fprintf(os.Stderr, "Warning: the filesystem of %s still reports %s; it may need to be expanded from inside the VM\n", name, formatBytes(after.DiskTotal))
+		}
+	}
+
+	// Keep the recorded disk size in step with the VM
+	md, err := metadata.Load(name)
+	if err == nil {
+		md.Disk = size
+		err = metadata.Save(md)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]any{"name": name, "disk": size, "previous_bytes": current, "bytes": resized})
+	}
+
+	fmt.Printf("Disk of %s grown from %s to %s.\n", name, formatBytes(current), formatBytes(resized))
+	return nil
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 20.0GiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
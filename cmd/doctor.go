@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// doctorReport is the result of environment detection
+type doctorReport struct {
+	OS                string   `json:"os"`
+	Arch              string   `json:"arch"`
+	IsWSL             bool     `json:"isWSL"`
+	RunningOnWindows  bool     `json:"runningOnWindows"`
+	UseWSLMultipass   bool     `json:"useWSLMultipass"`
+	MultipassCmd      string   `json:"multipassCmd"`
+	WSLDistro         string   `json:"wslDistro"`
+	RemoteHost        string   `json:"remoteHost,omitempty"`
+	MultipassResponds bool     `json:"multipassResponds"`
+	MultipassVersion  string   `json:"multipassVersion"`
+	Warnings          []string `json:"warnings"`
+}
+
+// NewDoctorCmd creates a command to diagnose the multipass environment
+func NewDoctorCmd() *cobra.Command {
+	var refresh bool
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the Multipass environment",
+		Long: `Print how mpkube detected the Multipass environment (WSL, Windows, the
+multipass command and WSL distribution used) and check that multipass responds.
+Include this output when reporting setup problems.
+
+The detected environment is cached in ~/.mpkube/env-cache.json for a day, or
+until PATH changes. After reinstalling multipass or changing WSL distributions,
+run 'mpkube doctor --refresh' to detect it again and update the cache.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if refresh {
+				multipass.SetEnvCache(false)
+			}
+			return runDoctor()
+		},
+	}
+
+	doctorCmd.Flags().BoolVar(&refresh, "refresh", false, "Ignore the cached environment and detect multipass again")
+
+	return doctorCmd
+}
+
+// runDoctor detects the environment and prints a report
+func runDoctor() error {
+	report := doctorReport{
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		IsWSL:            multipass.InWSL(),
+		RunningOnWindows: runtime.GOOS == "windows",
+		Warnings:         []string{},
+	}
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	} else {
+		report.UseWSLMultipass = mp.UseWSLMultipass
+		report.MultipassCmd = mp.MultipassCmd
+		report.WSLDistro = mp.WSLDistro
+		report.RemoteHost = mp.RemoteHost
+
+		version, err := mp.Version()
+		if err != nil {
+			report.Warnings = append(report.Warnings, err.Error())
+		} else {
+			report.MultipassResponds = true
+			report.MultipassVersion = version
+		}
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if len(report.Warnings) > 0 {
+		return fmt.Errorf("environment check found %d problem(s)", len(report.Warnings))
+	}
+
+	return nil
+}
+
+// printDoctorReport prints the report as a table
+func printDoctorReport(report doctorReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "OS/Arch:\t%s/%s\n", report.OS, report.Arch)
+	fmt.Fprintf(w, "IsWSL:\t%t\n", report.IsWSL)
+	fmt.Fprintf(w, "RunningOnWindows:\t%t\n", report.RunningOnWindows)
+	fmt.Fprintf(w, "UseWSLMultipass:\t%t\n", report.UseWSLMultipass)
+	fmt.Fprintf(w, "MultipassCmd:\t%s\n", report.MultipassCmd)
+	fmt.Fprintf(w, "WSLDistro:\t%s\n", report.WSLDistro)
+	if report.RemoteHost != "" {
+		fmt.Fprintf(w, "RemoteHost:\t%s\n", report.RemoteHost)
+	}
+	fmt.Fprintf(w, "Multipass responds:\t%t\n", report.MultipassResponds)
+	w.Flush()
+
+	if report.MultipassVersion != "" {
+		fmt.Printf("\nMultipass version:\n%s\n", report.MultipassVersion)
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, warning := range report.Warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewEventsCmd creates a command to show the recent events of a cluster
+func NewEventsCmd() *cobra.Command {
+	var watch bool
+	var since time.Duration
+
+	eventsCmd := &cobra.Command{
+		Use:   "events <name>",
+		Short: "Show recent Kubernetes events of a cluster",
+		Long: `Show the Kubernetes events of every namespace in a cluster, oldest first.
+
+Use --since to only show events seen within a recent window, e.g. --since 10m,
+or --watch to stream new events until interrupted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch && since > 0 {
+				return fmt.Errorf("--since cannot be combined with --watch")
+			}
+			if watch && jsonOutput() {
+				return fmt.Errorf("--watch does not support JSON output")
+			}
+			return showEvents(args[0], watch, since)
+		},
+	}
+
+	eventsCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Stream events as they happen")
+	eventsCmd.Flags().DurationVar(&since, "since", 0, "Only show events seen within this duration, e.g. 10m")
+
+	return eventsCmd
+}
+
+// showEvents prints the events of a cluster, or streams them if watch is set
+func showEvents(name string, watch bool, since time.Duration) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	if watch {
+		return withExitCode(k3s.WatchEvents(mp, name))
+	}
+
+	output, err := k3s.EventsJSON(mp, name)
+	if err != nil {
+		return err
+	}
+
+	events, err := k3s.ParseEvents(output)
+	if err != nil {
+		return err
+	}
+
+	if since > 0 {
+		cutoff := time.Now().Add(-since)
+		recent := events[:0]
+		for _, event := range events {
+			if !event.LastSeen.Before(cutoff) {
+				recent = append(recent, event)
+			}
+		}
+		events = recent
+	}
+
+	if jsonOutput() {
+		return printJSON(events)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No events found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tNAMESPACE\tTYPE\tREASON\tOBJECT\tMESSAGE")
+
+	for _, event := range events {
+		age := time.Since(event.LastSeen).Round(time.Second)
+		message := strings.ReplaceAll(strings.TrimSpace(event.Message), "\n", " ")
+		fmt.Fprintf(w, "%s ago\t%s\t%s\t%s\t%s\t%s\n", age, event.Namespace, event.Type, event.Reason, event.Object, message)
+	}
+
+	w.Flush()
+	return nil
+}
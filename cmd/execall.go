@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// execResult is the outcome of running a command in one cluster
+type execResult struct {
+	cluster string
+	output  string
+	stderr  string
+	err     error
+}
+
+// execRecord is the machine-readable form of an execResult
+type execRecord struct {
+	Cluster  string `json:"cluster"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Error    string `json:"error,omitempty"`
+}
+
+// record converts the result to its machine-readable form. The exit code is -1
+// when the command could not be run at all.
+func (r execResult) record() execRecord {
+	rec := execRecord{Cluster: r.cluster, Stdout: r.output, Stderr: r.stderr}
+	if r.err != nil {
+		rec.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(r.err, &exitErr) {
+			rec.ExitCode = exitErr.ExitCode()
+		}
+		rec.Error = r.err.Error()
+	}
+	return rec
+}
+
+// NewExecAllCmd creates a command to run a command in every cluster
+func NewExecAllCmd() *cobra.Command {
+	var parallel bool
+
+	execAllCmd := &cobra.Command{
+		Use:   "exec-all -- <command> [args...]",
+		Short: "Run a command in every cluster",
+		Long: `Run the same command inside every mpkube cluster VM and print the output
+grouped by cluster. A failure in one cluster does not stop the others.
+
+With -o jsonl, one JSON object with the cluster, exit code, stdout and stderr is
+printed per cluster as soon as it finishes; -o json prints them all as an array.
+
+  mpkube exec-all -- sudo kubectl get nodes`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execAll(args, parallel)
+		},
+	}
+
+	execAllCmd.Flags().BoolVarP(&parallel, "parallel", "p", false, "Run the command in all clusters concurrently")
+
+	return execAllCmd
+}
+
+// execAll runs command in every cluster and prints the grouped output
+func execAll(command []string, parallel bool) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	vms, err := clusterVMs(mp)
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	if len(vms) == 0 {
+		if jsonOutput() && !jsonlOutput() {
+			return printJSON([]execRecord{})
+		}
+		infoln("No K3s clusters found.")
+		return nil
+	}
+
+	// JSON Lines records are streamed as each cluster finishes, so guard stdout
+	var mu sync.Mutex
+	results := make([]execResult, len(vms))
+	run := func(i int) {
+		result := execResult{cluster: vms[i].Name}
+		if jsonOutput() {
+			result.output, result.stderr, result.err = mp.ExecSplit(vms[i].Name, command...)
+		} else {
+			result.output, result.err = mp.Exec(vms[i].Name, command...)
+		}
+		results[i] = result
+
+		if jsonlOutput() {
+			mu.Lock()
+			printJSON(result.record())
+			mu.Unlock()
+		}
+	}
+
+	if parallel {
+		var wg sync.WaitGroup
+		for i := range vms {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range vms {
+			run(i)
+			if !jsonOutput() {
+				printExecResult(results[i])
+			}
+		}
+	}
+
+	var errs []error
+	records := make([]execRecord, 0, len(results))
+	for _, result := range results {
+		// Sequential results are printed as they complete
+		if parallel && !jsonOutput() {
+			printExecResult(result)
+		}
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.cluster, result.err))
+		}
+		records = append(records, result.record())
+	}
+
+	if jsonOutput() {
+		if !jsonlOutput() {
+			if err := printJSON(records); err != nil {
+				return err
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if len(errs) > 0 {
+		fmt.Printf("\nCommand failed in %d of %d cluster(s):\n", len(errs), len(vms))
+		for _, err := range errs {
+			fmt.Printf("  %v\n", err)
+		}
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// printExecResult prints the output of one cluster under a header
+func printExecResult(result execResult) {
+	fmt.Printf("===== %s =====\n", result.cluster)
+	fmt.Println(strings.TrimRight(result.output, "\n"))
+	if result.err != nil {
+		fmt.Printf("(failed: %v)\n", result.err)
+	}
+	fmt.Println()
+}
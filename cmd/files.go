@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+)
+
+// expandPaths expands a leading ~ and environment variables in file path
+// flags in place, since shells leave them alone in forms like --file=~/x
+func expandPaths(paths ...*string) error {
+	for _, path := range paths {
+		expanded, err := config.ExpandPath(*path)
+		if err != nil {
+			return err
+		}
+		*path = expanded
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to path by writing a temporary file in the same
+// directory and renaming it into place, so readers never see a partial file.
+// When backup is set, an existing file is first copied to <path>.bak.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, backup bool) error {
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		// Keep the permissions of the file being replaced
+		perm = info.Mode().Perm()
+
+		if backup {
+			if err := copyFile(path, path+".bak", perm); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// copyFile copies src to dst, replacing dst if it exists
+func copyFile(src string, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewFindCmd creates a command to list images available to launch
+func NewFindCmd() *cobra.Command {
+	findCmd := &cobra.Command{
+		Use:   "find",
+		Short: "List images available for clusters",
+		Long:  `List the Multipass images and aliases that can be passed to 'create --image'.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return findImages()
+		},
+	}
+
+	return findCmd
+}
+
+// findImages prints the images multipass can launch
+func findImages() error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	images, err := mp.ListImages()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(nonNil(images))
+	}
+
+	if len(images) == 0 {
+		fmt.Println("No images found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tALIASES\tVERSION\tDESCRIPTION")
+
+	for _, image := range images {
+		name := image.Image
+		if image.Remote != "" {
+			name = image.Remote + ":" + name
+		}
+		description := strings.TrimSpace(image.OS + " " + image.Release)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, strings.Join(image.Aliases, ","), image.Version, description)
+	}
+
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewGetIPCmd creates a command to print the IP address of a cluster
+func NewGetIPCmd() *cobra.Command {
+	getIPCmd := &cobra.Command{
+		Use:     "get-ip <name>",
+		Aliases: []string{"ip"},
+		Short:   "Print the IP address of a cluster",
+		Long: `Print only the IP address of a cluster, for use in scripts. The IPv4 address
+is preferred; the IPv6 address is printed for IPv6-only VMs.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return getIP(args[0])
+		},
+	}
+
+	return getIPCmd
+}
+
+// getIP prints the IP address of a cluster
+func getIP(name string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	// Multipass reports placeholders while a VM is stopped or still booting
+	ip := vm.Address()
+	if ip == "" {
+		return fmt.Errorf("cluster '%s' has no IP address yet (state: %s)", name, vm.State)
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]string{"name": vm.Name, "ip": ip})
+	}
+
+	fmt.Println(ip)
+	return nil
+}
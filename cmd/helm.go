@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewHelmCmd creates a command to run helm against a cluster
+func NewHelmCmd() *cobra.Command {
+	helmCmd := &cobra.Command{
+		Use:   "helm <name> -- <helm args...>",
+		Short: "Run helm inside a cluster",
+		Long: `Run helm inside the cluster VM with KUBECONFIG pointed at the k3s config.
+Helm is installed in the VM first if it is missing. Output is streamed live and
+the exit code of helm is returned. Chart paths refer to files inside the VM; use
+'mpkube cp' to copy local charts over first.
+
+  mpkube helm <name> -- install myrelease ./chart`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 {
+				return fmt.Errorf("usage: mpkube helm <name> -- <helm args...>")
+			}
+			return runHelm(args[0], args[1:])
+		},
+	}
+
+	return helmCmd
+}
+
+// runHelm ensures helm is available in the cluster VM and runs it with args
+func runHelm(name string, args []string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	spinner := newSpinner("Checking for helm...")
+	spinner.Start()
+	if err := k3s.EnsureHelm(mp, name); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	if err := k3s.HelmInteractive(mp, name, args...); err != nil {
+		return withExitCode(fmt.Errorf("helm failed: %w", err))
+	}
+
+	return nil
+}
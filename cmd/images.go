@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// imageUsage is an image along with the mpkube clusters launched from it
+type imageUsage struct {
+	multipass.Image
+	Clusters []string `json:"clusters"`
+}
+
+// NewImagesCmd creates a command to show which images mpkube clusters use
+func NewImagesCmd() *cobra.Command {
+	imagesCmd := &cobra.Command{
+		Use:   "images",
+		Short: "Show which images are used by clusters",
+		Long: `List the Multipass images along with the mpkube clusters launched from each,
+so unused images can be told apart from ones clusters still depend on.
+
+Multipass has no command to remove a cached image. It deletes cached images
+that no instance has used for a while on its own; run 'mpkube delete
+--purge-volumes' to make sure deleted clusters no longer hold on to theirs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listImageUsage()
+		},
+	}
+
+	return imagesCmd
+}
+
+// listImageUsage prints every image and the clusters that use it
+func listImageUsage() error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	images, err := mp.ListImages()
+	if err != nil {
+		return err
+	}
+
+	vms, err := mp.GetK3sVMs()
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	usage := make([]imageUsage, 0, len(images))
+	for _, image := range images {
+		usage = append(usage, imageUsage{Image: image, Clusters: []string{}})
+	}
+
+	var unmatched []string
+	for _, vm := range vms {
+		// Prefer the image name recorded at create time, which is what was launched
+		launched := ""
+		md, err := metadata.Load(vm.Name)
+		if err == nil {
+			launched = md.Image
+		} else if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		matched := false
+		for i := range usage {
+			if imageUsedBy(usage[i].Image, launched, vm.Image) {
+				usage[i].Clusters = append(usage[i].Clusters, vm.Name)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, vm.Name)
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(usage)
+	}
+
+	if len(usage) == 0 {
+		fmt.Println("No images found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tALIASES\tVERSION\tCLUSTERS")
+
+	for _, u := range usage {
+		name := u.Image.Image
+		if u.Remote != "" {
+			name = u.Remote + ":" + name
+		}
+		clusters := "-"
+		if len(u.Clusters) > 0 {
+			clusters = strings.Join(u.Clusters, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, strings.Join(u.Aliases, ","), u.Version, clusters)
+	}
+
+	w.Flush()
+
+	if len(unmatched) > 0 {
+		fmt.Printf("\nLaunched from images not listed above: %s\n", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// imageUsedBy reports whether a cluster was launched from image, given the
+// image name recorded in its metadata and the release multipass reports for it,
+// e.g. "Ubuntu 22.04 LTS"
+func imageUsedBy(image multipass.Image, launched string, release string) bool {
+	if launched != "" {
+		for _, name := range image.Names() {
+			if name == launched {
+				return true
+			}
+		}
+		return false
+	}
+
+	return release != "" && strings.EqualFold(release, strings.TrimSpace(image.OS+" "+image.Release))
+}
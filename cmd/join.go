@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// joinResult is the machine-readable result of joining a worker to a cluster
+type joinResult struct {
+	Name   string `json:"name"`
+	IP     string `json:"ip"`
+	Server string `json:"server"`
+}
+
+// NewJoinCmd creates a command to add a worker node to an existing cluster
+func NewJoinCmd() *cobra.Command {
+	var opts createOptions
+
+	joinCmd := &cobra.Command{
+		Use:   "join <cluster> [name]",
+		Short: "Add a worker node to an existing cluster",
+		Long: `Launch a new Multipass VM and join it to an existing cluster as a k3s agent.
+The worker is named <cluster>-worker-N unless a name is given. Unless set with
+flags, the worker gets the same resources, image and k3s version as the server.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				opts.name = args[1]
+			}
+			return joinCluster(cmd, args[0], opts)
+		},
+	}
+
+	joinCmd.Flags().IntVarP(&opts.cpus, "cpus", "c", 2, "Number of CPUs for the VM")
+	joinCmd.Flags().StringVarP(&opts.memory, "memory", "m", "2G", "Memory allocation for the VM, with a K, M or G unit (e.g. 512M or 4G)")
+	joinCmd.Flags().StringVarP(&opts.disk, "disk", "d", "10G", "Disk space for the VM, with a K, M or G unit (e.g. 20G)")
+	joinCmd.Flags().StringVarP(&opts.image, "image", "i", "22.04", "Multipass image or alias to launch (see 'multipass find')")
+
+	return joinCmd
+}
+
+// joinCluster launches a worker VM and joins it to cluster
+func joinCluster(cmd *cobra.Command, cluster string, opts createOptions) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	server := multipass.ClusterVMName(cluster)
+	serverVM, err := mp.GetVMByName(server)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", server, err)
+	}
+
+	// Inherit the server's spec for anything not set on the command line
+	serverMD, err := metadata.Load(server)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if serverMD != nil {
+		if !cmd.Flags().Changed("cpus") {
+			opts.cpus = serverMD.CPUs
+		}
+		if !cmd.Flags().Changed("memory") {
+			opts.memory = serverMD.Memory
+		}
+		if !cmd.Flags().Changed("disk") {
+			opts.disk = serverMD.Disk
+		}
+		if !cmd.Flags().Changed("image") {
+			opts.image = serverMD.Image
+		}
+	}
+
+	result, err := addWorker(mp, serverVM, serverMD, opts)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(result)
+	}
+
+	fmt.Printf("\nWorker %s (IP: %s) joined cluster %s.\n", result.Name, result.IP, server)
+	fmt.Printf("Run 'mpkube nodes %s' to see the cluster's nodes.\n", server)
+	return nil
+}
+
+// addWorker launches a worker VM with the resources in opts and joins it to the
+// cluster running in serverVM. serverMD, if not nil, records the new worker.
+func addWorker(mp *multipass.MultipassEnv, serverVM *multipass.VM, serverMD *metadata.Cluster, opts createOptions) (*joinResult, error) {
+	server := serverVM.Name
+
+	var err error
+	if opts.memory, err = config.NormalizeSize(opts.memory); err != nil {
+		return nil, fmt.Errorf("invalid --memory: %w", err)
+	}
+	if opts.disk, err = config.NormalizeSize(opts.disk); err != nil {
+		return nil, fmt.Errorf("invalid --disk: %w", err)
+	}
+
+	name := opts.name
+	if name == "" {
+		name, err = nextWorkerName(mp, server)
+		if err != nil {
+			return nil, err
+		}
+	}
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err == nil {
+		return nil, fmt.Errorf("VM '%s' already exists", name)
+	}
+
+	if err := mp.ValidateImage(opts.image); err != nil {
+		return nil, err
+	}
+
+	dataDir := ""
+	if serverMD != nil {
+		dataDir = serverMD.DataDir
+	}
+	token, err := k3s.GetNodeToken(mp, server, dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Agents must not be newer than the server, so install the same version
+	version, err := k3s.GetVersion(mp, server)
+	if err != nil {
+		return nil, err
+	}
+	opts.install.Version = version
+
+	infof("Joining worker %s to cluster %s\n", name, server)
+
+	if err := launchVM(mp, name, opts); err != nil {
+		return nil, err
+	}
+
+	md := &metadata.Cluster{
+		Name:       name,
+		CPUs:       opts.cpus,
+		Memory:     opts.memory,
+		Disk:       opts.disk,
+		Image:      opts.image,
+		K3sVersion: version,
+		CreatedAt:  time.Now().UTC(),
+		Server:     server,
+	}
+	if err := metadata.Save(md); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	spinner := newSpinner("Installing k3s agent...")
+	spinner.Start()
+	if err := k3s.InstallAgent(mp, name, k3s.ServerURL(serverVM), token, opts.install); err != nil {
+		spinner.Stop("failed")
+		return nil, fmt.Errorf("failed to install k3s agent: %w", err)
+	}
+	spinner.Stop("done")
+
+	if serverMD != nil {
+		serverMD.Workers = append(serverMD.Workers, name)
+		if err := metadata.Save(serverMD); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM details: %w", err)
+	}
+
+	return &joinResult{Name: name, IP: vm.Address(), Server: server}, nil
+}
+
+// nextWorkerName returns the first unused <server>-worker-N name
+func nextWorkerName(mp *multipass.MultipassEnv, server string) (string, error) {
+	vms, err := mp.ListVMs()
+	if err != nil {
+		return "", fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	prefix := server + "-worker-"
+	highest := 0
+	for _, vm := range vms {
+		if n, err := strconv.Atoi(strings.TrimPrefix(vm.Name, prefix)); err == nil && strings.HasPrefix(vm.Name, prefix) && n > highest {
+			highest = n
+		}
+	}
+
+	return fmt.Sprintf("%s%d", prefix, highest+1), nil
+}
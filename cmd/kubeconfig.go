@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/rodneyxr/mpkube/pkg/config"
 	"github.com/rodneyxr/mpkube/pkg/k3s"
 	"github.com/rodneyxr/mpkube/pkg/multipass"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // NewKubeconfigCmd creates a command to manage kubeconfigs
@@ -22,6 +27,7 @@ func NewKubeconfigCmd() *cobra.Command {
 	// Add subcommands
 	kubeconfigCmd.AddCommand(NewKubeconfigGetCmd())
 	kubeconfigCmd.AddCommand(NewKubeconfigMergeCmd())
+	kubeconfigCmd.AddCommand(NewKubeconfigPurgeCmd())
 
 	return kubeconfigCmd
 }
@@ -29,22 +35,41 @@ func NewKubeconfigCmd() *cobra.Command {
 // NewKubeconfigGetCmd creates a command to get kubeconfig for a specific cluster
 func NewKubeconfigGetCmd() *cobra.Command {
 	var outputFile string
+	var validate bool
+	var tunnel bool
+	var tunnelPort int
 
 	getCmd := &cobra.Command{
 		Use:   "get [mpkube-name]",
 		Short: "Get kubeconfig for a specific cluster",
-		Long:  `Extract kubeconfig from a specific k3s cluster and print it or save it to a file.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Extract kubeconfig from a specific k3s cluster and print it or save it to a file.
+
+The current context of the kubeconfig is always the cluster itself. With
+--validate, the kubeconfig is also checked by calling the API server's /version
+endpoint, which catches a VM IP that is not reachable from this host.
+
+With --tunnel, the server is set to 127.0.0.1:<tunnel-port> for use with
+'mpkube tunnel', for hosts that cannot reach the VM's IP directly.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var clusterName string
 			if len(args) > 0 {
 				clusterName = args[0]
 			}
-			return getKubeconfig(clusterName, outputFile)
+			if !tunnel {
+				tunnelPort = 0
+			}
+			if err := expandPaths(&outputFile); err != nil {
+				return err
+			}
+			return getKubeconfig(clusterName, outputFile, validate, tunnelPort)
 		},
 	}
 
-	getCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file to save kubeconfig (prints to stdout if not specified)")
+	getCmd.Flags().StringVarP(&outputFile, "file", "f", "", "File or directory to save the kubeconfig to (prints to stdout if not specified)")
+	getCmd.Flags().BoolVar(&tunnel, "tunnel", false, "Point the kubeconfig at a local 'mpkube tunnel' instead of the VM's IP")
+	getCmd.Flags().IntVar(&tunnelPort, "tunnel-port", k3s.APIServerPort, "Local port of the tunnel used with --tunnel")
+	getCmd.Flags().BoolVar(&validate, "validate", false, "Check that the API server is reachable with the kubeconfig")
 
 	return getCmd
 }
@@ -52,23 +77,65 @@ func NewKubeconfigGetCmd() *cobra.Command {
 // NewKubeconfigMergeCmd creates a command to merge kubeconfigs from all clusters
 func NewKubeconfigMergeCmd() *cobra.Command {
 	var outputFile string
+	var noBackup bool
+	var includeEnv bool
+	var contextPrefix string
+	var mergeOpts k3s.MergeOptions
 
 	mergeCmd := &cobra.Command{
 		Use:   "merge",
 		Short: "Merge kubeconfigs from all clusters",
-		Long:  `Merge kubeconfigs from all k3s clusters created with this tool into a single config.`,
+		Long: `Merge kubeconfigs from all k3s clusters created with this tool into a single config.
+
+When --file points at an existing file, it is backed up to <file>.bak
+before being replaced unless --no-backup is set.
+
+With --include-env, the kubeconfig files listed in the KUBECONFIG environment
+variable are merged in as well, so the result also keeps your existing clusters.
+--context-prefix, e.g. dev/, is prepended to the names of the mpkube clusters
+only, setting them apart from those clusters in kubectl config get-contexts.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return mergeKubeconfigs(outputFile)
+			if err := expandPaths(&outputFile); err != nil {
+				return err
+			}
+			return mergeKubeconfigs(outputFile, !noBackup, includeEnv, contextPrefix, mergeOpts)
 		},
 	}
 
-	mergeCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file to save merged kubeconfig (prints to stdout if not specified)")
+	mergeCmd.Flags().StringVarP(&outputFile, "file", "f", "", "File to save the merged kubeconfig to (prints to stdout if not specified)")
+	mergeCmd.Flags().BoolVar(&mergeOpts.SetCurrentContext, "set-current-context", false, "Set the current context of the merged config to the first cluster")
+	mergeCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Do not back up an existing output file to <output>.bak")
+	mergeCmd.Flags().BoolVar(&includeEnv, "include-env", false, "Also merge the kubeconfig files listed in $KUBECONFIG")
+	mergeCmd.Flags().BoolVar(&mergeOpts.Overwrite, "overwrite", false, "Replace entries with the same name instead of keeping the first one")
+	mergeCmd.Flags().StringVar(&contextPrefix, "context-prefix", "", "Prefix the context, cluster and user names of mpkube clusters, e.g. dev/ (not applied to --include-env configs)")
 
 	return mergeCmd
 }
 
-// getKubeconfig retrieves kubeconfig for a specific cluster
-func getKubeconfig(clusterName string, outputFile string) error {
+// kubeconfigFileName returns the file name mpkube saves the kubeconfig of a cluster as
+func kubeconfigFileName(name string) string {
+	return "kubeconfig-" + name
+}
+
+// defaultKubeconfigPath returns where the kubeconfig of a cluster is saved by
+// default, in the directory set by the kubeconfig.dir config key
+func defaultKubeconfigPath(name string) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := cfg.KubeconfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, kubeconfigFileName(name)), nil
+}
+
+// getKubeconfig retrieves kubeconfig for a specific cluster, optionally checking
+// that it can reach the API server. A non-zero tunnelPort points the kubeconfig
+// at a local tunnel on that port.
+func getKubeconfig(clusterName string, outputFile string, validate bool, tunnelPort int) error {
 	mp, err := multipass.NewMultipassEnv()
 	if err != nil {
 		return fmt.Errorf("failed to initialize multipass environment: %w", err)
@@ -76,7 +143,7 @@ func getKubeconfig(clusterName string, outputFile string) error {
 
 	// If no cluster name provided, list available clusters
 	if clusterName == "" {
-		vms, err := mp.GetK3sVMs()
+		vms, err := clusterVMs(mp)
 		if err != nil {
 			return fmt.Errorf("failed to list clusters: %w", err)
 		}
@@ -96,10 +163,8 @@ func getKubeconfig(clusterName string, outputFile string) error {
 		}
 	}
 
-	// Add mpkube- prefix if not present
-	if !strings.HasPrefix(clusterName, "mpkube-") {
-		clusterName = fmt.Sprintf("mpkube-%s", clusterName)
-	}
+	// Add cluster prefix if not present
+	clusterName = multipass.ClusterVMName(clusterName)
 
 	// Get kubeconfig from the specified cluster
 	kubeconfig, err := k3s.GetKubeconfig(mp, clusterName)
@@ -107,8 +172,33 @@ func getKubeconfig(clusterName string, outputFile string) error {
 		return fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
 
+	if tunnelPort != 0 {
+		kubeconfig, err = k3s.TunnelKubeconfig(kubeconfig, tunnelPort)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Run 'mpkube tunnel %s --port %d' to use this kubeconfig.\n", clusterName, tunnelPort)
+	} else {
+		warnRemoteKubeconfig(clusterName)
+	}
+
+	if validate {
+		version, err := k3s.ValidateKubeconfig(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("kubeconfig validation failed: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Kubeconfig validated: API server %s is reachable.\n", version)
+	}
+
 	// Save or print the kubeconfig
 	if outputFile != "" {
+		outputFile = mp.LocalPath(outputFile)
+
+		// A directory gets the same file name as kubeconfigs saved by create
+		if info, err := os.Stat(outputFile); err == nil && info.IsDir() {
+			outputFile = filepath.Join(outputFile, kubeconfigFileName(clusterName))
+		}
+
 		// Ensure directory exists
 		dir := filepath.Dir(outputFile)
 		if dir != "" && dir != "." {
@@ -132,19 +222,19 @@ func getKubeconfig(clusterName string, outputFile string) error {
 }
 
 // mergeKubeconfigs merges kubeconfigs from all clusters
-func mergeKubeconfigs(outputFile string) error {
+func mergeKubeconfigs(outputFile string, backup bool, includeEnv bool, contextPrefix string, mergeOpts k3s.MergeOptions) error {
 	mp, err := multipass.NewMultipassEnv()
 	if err != nil {
 		return fmt.Errorf("failed to initialize multipass environment: %w", err)
 	}
 
 	// Get all clusters
-	vms, err := mp.GetK3sVMs()
+	vms, err := clusterVMs(mp)
 	if err != nil {
 		return fmt.Errorf("failed to list clusters: %w", err)
 	}
 
-	if len(vms) == 0 {
+	if len(vms) == 0 && !includeEnv {
 		return fmt.Errorf("no clusters found")
 	}
 
@@ -156,31 +246,34 @@ func mergeKubeconfigs(outputFile string) error {
 			fmt.Printf("Warning: Failed to get kubeconfig for %s: %v\n", vm.Name, err)
 			continue
 		}
+		if contextPrefix != "" {
+			if kubeconfig, err = k3s.PrefixKubeconfig(kubeconfig, contextPrefix); err != nil {
+				return err
+			}
+		}
 		kubeconfigs = append(kubeconfigs, kubeconfig)
 	}
 
+	if includeEnv {
+		kubeconfigs = append(kubeconfigs, envKubeconfigs()...)
+	}
+
 	if len(kubeconfigs) == 0 {
 		return fmt.Errorf("failed to get any kubeconfigs")
 	}
 
 	// Merge kubeconfigs
-	mergedConfig, err := k3s.MergeKubeconfigs(kubeconfigs)
+	mergedConfig, err := k3s.MergeKubeconfigs(kubeconfigs, mergeOpts)
 	if err != nil {
 		return fmt.Errorf("failed to merge kubeconfigs: %w", err)
 	}
 
 	// Save or print the merged kubeconfig
 	if outputFile != "" {
-		// Ensure directory exists
-		dir := filepath.Dir(outputFile)
-		if dir != "" && dir != "." {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return fmt.Errorf("failed to create directory: %w", err)
-			}
-		}
+		outputFile = mp.LocalPath(outputFile)
 
-		// Write kubeconfig to file
-		if err := os.WriteFile(outputFile, []byte(mergedConfig), 0644); err != nil {
+		// The output is often an existing config such as ~/.kube/config, so back it up and replace it atomically
+		if err := writeFileAtomic(outputFile, []byte(mergedConfig), 0644, backup); err != nil {
 			return fmt.Errorf("failed to write kubeconfig: %w", err)
 		}
 
@@ -192,3 +285,161 @@ func mergeKubeconfigs(outputFile string) error {
 
 	return nil
 }
+
+// envKubeconfigs reads the kubeconfig files listed in the KUBECONFIG environment
+// variable, which uses the platform's path list separator. Missing or unreadable
+// files are skipped with a warning.
+func envKubeconfigs() []string {
+	var kubeconfigs []string
+	for _, path := range filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)) {
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read kubeconfig %s: %v\n", path, err)
+			continue
+		}
+		kubeconfigs = append(kubeconfigs, string(data))
+	}
+	return kubeconfigs
+}
+
+// NewKubeconfigPurgeCmd creates a command to remove kubeconfig entries for deleted clusters
+func NewKubeconfigPurgeCmd() *cobra.Command {
+	var force bool
+	var kubeconfigPath string
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove kubeconfig entries for clusters that no longer exist",
+		Long: `Remove mpkube-* contexts, and the clusters and users they reference, from a
+kubeconfig when the cluster VM no longer exists. The kubeconfig is backed up to
+<path>.bak before it is rewritten.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := expandPaths(&kubeconfigPath); err != nil {
+				return err
+			}
+			return purgeKubeconfig(kubeconfigPath, force)
+		},
+	}
+
+	purgeCmd.Flags().BoolVarP(&force, "force", "f", false, "Remove entries without confirmation")
+	purgeCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", clientcmd.RecommendedHomeFile, "Kubeconfig file to clean up")
+
+	return purgeCmd
+}
+
+// purgeKubeconfig removes mpkube contexts whose VMs are gone from the kubeconfig at path
+func purgeKubeconfig(path string, force bool) error {
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	vms, err := clusterVMs(mp)
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	live := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		live[vm.Name] = true
+	}
+
+	var orphaned []string
+	for name := range config.Contexts {
+		if strings.HasPrefix(name, multipass.ClusterPrefix()) && !live[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+
+	if len(orphaned) == 0 {
+		fmt.Println("No orphaned mpkube contexts found.")
+		return nil
+	}
+
+	// Confirmation unless --force or --assume-yes is used
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "The following contexts in %s have no matching cluster:\n", path)
+	for _, name := range orphaned {
+		fmt.Fprintf(&prompt, "  %s\n", name)
+	}
+	prompt.WriteString("Remove them? [y/N]: ")
+
+	ok, err := Confirm(prompt.String(), force || assumeYes)
+	if errors.Is(err, errNotInteractive) {
+		return fmt.Errorf("%w; pass --force or --assume-yes to remove them without confirmation", err)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Purge cancelled.")
+		return nil
+	}
+
+	removeContexts(config, orphaned)
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600, true); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	fmt.Printf("Removed %d context(s) from %s.\n", len(orphaned), path)
+	return nil
+}
+
+// removeContexts deletes the named contexts and any clusters or users that only they referenced
+func removeContexts(config *api.Config, names []string) {
+	for _, name := range names {
+		context := config.Contexts[name]
+		delete(config.Contexts, name)
+
+		if config.CurrentContext == name {
+			config.CurrentContext = ""
+		}
+
+		if context == nil {
+			continue
+		}
+		if !clusterInUse(config, context.Cluster) {
+			delete(config.Clusters, context.Cluster)
+		}
+		if !authInfoInUse(config, context.AuthInfo) {
+			delete(config.AuthInfos, context.AuthInfo)
+		}
+	}
+}
+
+// clusterInUse reports whether any remaining context references the cluster
+func clusterInUse(config *api.Config, cluster string) bool {
+	for _, context := range config.Contexts {
+		if context.Cluster == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// authInfoInUse reports whether any remaining context references the user
+func authInfoInUse(config *api.Config, authInfo string) bool {
+	for _, context := range config.Contexts {
+		if context.AuthInfo == authInfo {
+			return true
+		}
+	}
+	return false
+}
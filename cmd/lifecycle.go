@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// clusterAction is a VM lifecycle operation that start and stop apply to clusters
+type clusterAction struct {
+	// progress and done describe the action, e.g. "Starting" and "started"
+	progress string
+	done     string
+	run      func(mp *multipass.MultipassEnv, name string) error
+}
+
+// actionReport is the machine-readable result of start or stop
+type actionReport struct {
+	Clusters []string `json:"clusters"`
+	Errors   []string `json:"errors"`
+}
+
+// namesOrAll validates positional cluster names against an --all flag
+func namesOrAll(all *bool) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if *all && len(args) > 0 {
+			return fmt.Errorf("cluster names cannot be combined with --all")
+		}
+		if !*all && len(args) == 0 {
+			return fmt.Errorf("requires at least 1 cluster name or --all")
+		}
+		return nil
+	}
+}
+
+// runClusterAction applies action to the named clusters, or every cluster with
+// all, and to their workers, then prints a summary. A failure for one VM does
+// not stop the others.
+func runClusterAction(action clusterAction, names []string, all bool, parallel bool) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	var errs []error
+	var clusters []multipass.VM
+
+	if all {
+		clusters, err = clusterVMs(mp)
+		if err != nil {
+			return fmt.Errorf("failed to list clusters: %w", err)
+		}
+	} else {
+		for _, name := range names {
+			// Add cluster prefix if not present
+			name = multipass.ClusterVMName(name)
+
+			vm, err := mp.GetVMByName(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cluster '%s' not found: %w", name, err))
+				continue
+			}
+			clusters = append(clusters, *vm)
+		}
+	}
+
+	// Workers follow their server
+	vms, _ := withWorkers(mp, clusters)
+	var targets []string
+	for _, vm := range vms {
+		targets = append(targets, vm.Name)
+	}
+
+	// Clusters that were not found count towards the summary too
+	requested := len(targets) + len(errs)
+
+	results := make([]error, len(targets))
+	if parallel {
+		infof("%s %d VM(s)...\n", action.progress, len(targets))
+		var wg sync.WaitGroup
+		for i, name := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = action.run(mp, name)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, name := range targets {
+			infof("%s '%s'...\n", action.progress, name)
+			results[i] = action.run(mp, name)
+		}
+	}
+
+	var done []string
+	for i, name := range targets {
+		if results[i] != nil {
+			errs = append(errs, results[i])
+			continue
+		}
+		done = append(done, name)
+	}
+
+	if jsonOutput() {
+		report := actionReport{Clusters: nonNil(done), Errors: []string{}}
+		for _, err := range errs {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		return errors.Join(errs...)
+	}
+
+	if requested == 0 {
+		fmt.Println("No K3s clusters found.")
+		return nil
+	}
+
+	fmt.Printf("%s %d of %d VM(s).\n", strings.ToUpper(action.done[:1])+action.done[1:], len(done), requested)
+	for _, err := range errs {
+		fmt.Printf("  FAILED: %v\n", err)
+	}
+
+	return errors.Join(errs...)
+}
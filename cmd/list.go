@@ -1,52 +1,386 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
+	"github.com/rodneyxr/mpkube/pkg/metadata"
 	"github.com/rodneyxr/mpkube/pkg/multipass"
 	"github.com/spf13/cobra"
 )
 
 // NewListCmd creates a command to list all k3s clusters
 func NewListCmd() *cobra.Command {
+	var all bool
+	var selector string
+	var filter vmFilter
+	var watch bool
+	var interval time.Duration
+	var noHeader bool
+
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all k3s clusters",
-		Long:  `List all Kubernetes clusters created with this tool in Multipass VMs.`,
+		Long: `List all Kubernetes clusters created with this tool in Multipass VMs.
+
+Use --selector to show only clusters with the given labels, which are set with
+create --label:
+
+  mpkube list --selector team=backend,env=dev
+
+Use --state and --image to filter by VM state or image. All filters must match.
+
+Use --watch to redraw the table every --interval until interrupted, e.g. to
+follow a cluster being created. With --quiet the screen is not cleared and the
+table is only printed again when it changes.
+
+Use --no-header to leave out the header row of the table, e.g. to pipe it into
+awk or cut. It has no effect on JSON or YAML output.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listClusters()
+			if all && selector != "" {
+				return fmt.Errorf("--selector cannot be combined with --all")
+			}
+			if watch {
+				if all {
+					return fmt.Errorf("--watch cannot be combined with --all")
+				}
+				if jsonOutput() {
+					return fmt.Errorf("--watch does not support JSON output")
+				}
+				if interval <= 0 {
+					return fmt.Errorf("--interval must be positive")
+				}
+			}
+			return listClusters(all, selector, filter, watch, interval, !noHeader)
 		},
 	}
 
+	listCmd.Flags().BoolVarP(&all, "all", "a", false, "Show all Multipass VMs, including ones not managed by mpkube")
+	listCmd.Flags().StringVar(&filter.state, "state", "", "Only show VMs in this state, e.g. Running or Stopped")
+	listCmd.Flags().StringVar(&filter.image, "image", "", "Only show VMs whose image contains this text, e.g. 24.04")
+	listCmd.Flags().StringVarP(&selector, "selector", "l", "", "Only show clusters with these labels, as key=value[,key=value...]")
+	listCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Redraw the table every --interval until interrupted")
+	listCmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "How often to refresh the table with --watch")
+	listCmd.Flags().BoolVar(&noHeader, "no-header", false, "Do not print the header row of the table, for scripting")
+
 	return listCmd
 }
 
-// listClusters lists all clusters managed by this tool
-func listClusters() error {
+// listedCluster is a cluster VM along with the labels recorded in its metadata
+type listedCluster struct {
+	multipass.VM
+	Labels map[string]string `json:"labels,omitempty"`
+
+	metadata *metadata.Cluster
+}
+
+// vmFilter holds the --state and --image filters of the list command
+type vmFilter struct {
+	state string
+	image string
+}
+
+// apply returns the VMs that match every set filter
+func (f vmFilter) apply(vms []multipass.VM) []multipass.VM {
+	var matched []multipass.VM
+	for _, vm := range vms {
+		if f.state != "" && !strings.EqualFold(vm.State, f.state) {
+			continue
+		}
+		if f.image != "" && !strings.Contains(vm.Image, f.image) {
+			continue
+		}
+		matched = append(matched, vm)
+	}
+	return matched
+}
+
+// listClusters lists all clusters managed by this tool, or every VM if all is set.
+// If selector is set, only clusters with matching labels are listed. header
+// controls whether tables start with a header row.
+func listClusters(all bool, selector string, filter vmFilter, watch bool, interval time.Duration, header bool) error {
+	labelSelector, err := parseLabels(splitSelector(selector))
+	if err != nil {
+		return fmt.Errorf("invalid --selector: %w", err)
+	}
+
 	mp, err := multipass.NewMultipassEnv()
 	if err != nil {
 		return fmt.Errorf("failed to initialize multipass environment: %w", err)
 	}
 
+	if all {
+		return listAllVMs(mp, filter, header)
+	}
+
+	if watch {
+		return watchClusters(mp, labelSelector, filter, interval, header)
+	}
+
 	// Get all VMs that have our cluster prefix
+	vms, err := clusterVMs(mp)
+	if err != nil {
+		return fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	clusters := labeledClusters(filter.apply(vms), labelSelector)
+
+	if jsonOutput() {
+		return printJSON(nonNil(clusters))
+	}
+
+	printClusters(os.Stdout, mp, clusters, header)
+	return nil
+}
+
+// watchClusters redraws the cluster table every interval until interrupted.
+// With --quiet the screen is not cleared and the table is only printed when it changes.
+func watchClusters(mp *multipass.MultipassEnv, selector map[string]string, filter vmFilter, interval time.Duration, header bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := ""
+	for {
+		vms, err := clusterVMs(mp)
+		if err != nil {
+			return fmt.Errorf("failed to list VMs: %w", err)
+		}
+
+		var table bytes.Buffer
+		printClusters(&table, mp, labeledClusters(filter.apply(vms), selector), header)
+
+		if !quiet {
+			// Move the cursor home and clear the screen before redrawing
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Every %s, updated %s (Ctrl-C to stop)\n\n", interval, time.Now().Format("15:04:05"))
+			fmt.Print(table.String())
+		} else if table.String() != previous {
+			if previous != "" {
+				fmt.Println()
+			}
+			fmt.Print(table.String())
+		}
+		previous = table.String()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printClusters writes the cluster table to out, with extra columns for wide
+// output. Without a header, an empty table prints nothing so scripts see no rows.
+func printClusters(out io.Writer, mp *multipass.MultipassEnv, clusters []listedCluster, header bool) {
+	if len(clusters) == 0 {
+		if header {
+			fmt.Fprintln(out, "No K3s clusters found.")
+		}
+		return
+	}
+
+	if wideOutput() {
+		printWideClusters(out, mp, clusters, header)
+		return
+	}
+
+	// Print table of clusters
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if header {
+		fmt.Fprintln(w, "NAME\tSTATE\tIP\tIMAGE")
+	}
+
+	for _, c := range clusters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Name, c.State, c.IPv4, c.Image)
+	}
+
+	w.Flush()
+}
+
+// printWideClusters writes the cluster table with the resources and k3s version
+// of each cluster to out. VM details are fetched in parallel since each takes a
+// multipass call; stopped VMs fall back to the sizes recorded at create time.
+func printWideClusters(out io.Writer, mp *multipass.MultipassEnv, clusters []listedCluster, header bool) {
+	infos := make([]*multipass.VMInfo, len(clusters))
+	var wg sync.WaitGroup
+	for i, c := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := mp.GetVMInfo(c.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				return
+			}
+			infos[i] = info
+		}()
+	}
+	wg.Wait()
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if header {
+		fmt.Fprintln(w, "NAME\tSTATE\tIP\tIMAGE\tCPUS\tMEMORY\tDISK\tK3S-VERSION")
+	}
+
+	for i, c := range clusters {
+		cpus, memory, disk := "-", "-", "-"
+		if c.metadata.CPUs > 0 {
+			cpus = strconv.Itoa(c.metadata.CPUs)
+		}
+		if c.metadata.Memory != "" {
+			memory = c.metadata.Memory
+		}
+		if c.metadata.Disk != "" {
+			disk = c.metadata.Disk
+		}
+		if info := infos[i]; info != nil {
+			if info.CPUs > 0 {
+				cpus = strconv.Itoa(info.CPUs)
+			}
+			if info.MemoryTotal > 0 {
+				memory = formatBytes(info.MemoryTotal)
+			}
+			if info.DiskTotal > 0 {
+				disk = formatBytes(info.DiskTotal)
+			}
+		}
+
+		version := c.metadata.K3sVersion
+		if version == "" {
+			version = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.State, c.IPv4, c.Image, cpus, memory, disk, version)
+	}
+
+	w.Flush()
+}
+
+// clusterVMs returns the mpkube VMs that run a k3s server, leaving out VMs that
+// joined another cluster as agents, such as workers added with join or agents of
+// an external server created with create --server-url
+func clusterVMs(mp *multipass.MultipassEnv) ([]multipass.VM, error) {
 	vms, err := mp.GetK3sVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []multipass.VM
+	for _, vm := range vms {
+		if md, err := metadata.Load(vm.Name); err == nil && md.IsAgent() {
+			continue
+		}
+		clusters = append(clusters, vm)
+	}
+	return clusters, nil
+}
+
+// withWorkers returns vms followed by the existing workers of each of them that
+// are not already in vms, so workers are not left behind without their server.
+// The returned map gives the server of each added worker.
+func withWorkers(mp *multipass.MultipassEnv, vms []multipass.VM) ([]multipass.VM, map[string]string) {
+	seen := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		seen[vm.Name] = true
+	}
+
+	all := vms
+	workerOf := make(map[string]string)
+	for _, vm := range vms {
+		md, err := metadata.Load(vm.Name)
+		if err != nil {
+			continue
+		}
+		for _, worker := range md.Workers {
+			if seen[worker] {
+				continue
+			}
+			// Workers deleted on their own are still recorded on the server
+			workerVM, err := mp.GetVMByName(worker)
+			if err != nil {
+				continue
+			}
+			seen[worker] = true
+			workerOf[worker] = vm.Name
+			all = append(all, *workerVM)
+		}
+	}
+	return all, workerOf
+}
+
+// splitSelector splits a comma-separated label selector into key=value pairs
+func splitSelector(selector string) []string {
+	if selector == "" {
+		return nil
+	}
+	return strings.Split(selector, ",")
+}
+
+// labeledClusters attaches metadata labels to vms and drops the ones that do
+// not match selector. Clusters without readable metadata have no labels.
+func labeledClusters(vms []multipass.VM, selector map[string]string) []listedCluster {
+	var clusters []listedCluster
+	for _, vm := range vms {
+		md, err := metadata.Load(vm.Name)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			md = &metadata.Cluster{Name: vm.Name}
+		}
+
+		if !md.MatchesLabels(selector) {
+			continue
+		}
+		clusters = append(clusters, listedCluster{VM: vm, Labels: md.Labels, metadata: md})
+	}
+	return clusters
+}
+
+// listAllVMs lists every Multipass VM and whether mpkube manages it
+func listAllVMs(mp *multipass.MultipassEnv, filter vmFilter, header bool) error {
+	vms, err := mp.ListVMs()
 	if err != nil {
 		return fmt.Errorf("failed to list VMs: %w", err)
 	}
+	vms = filter.apply(vms)
+
+	if jsonOutput() {
+		return printJSON(nonNil(vms))
+	}
 
 	if len(vms) == 0 {
-		fmt.Println("No K3s clusters found.")
+		if header {
+			fmt.Println("No Multipass VMs found.")
+		}
 		return nil
 	}
 
-	// Print table of clusters
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAME\tSTATE\tIP\tIMAGE")
+	if header {
+		fmt.Fprintln(w, "NAME\tSTATE\tIP\tIMAGE\tMANAGED")
+	}
 
 	for _, vm := range vms {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", vm.Name, vm.State, vm.IPv4, vm.Image)
+		managed := "no"
+		if vm.IsK3s {
+			managed = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", vm.Name, vm.State, vm.IPv4, vm.Image, managed)
 	}
 
 	w.Flush()
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewNetworksCmd creates a command to list networks available to clusters
+func NewNetworksCmd() *cobra.Command {
+	networksCmd := &cobra.Command{
+		Use:   "networks",
+		Short: "List networks available for clusters",
+		Long:  `List the Multipass networks that can be passed to 'create --network'.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listNetworks()
+		},
+	}
+
+	return networksCmd
+}
+
+// listNetworks prints the networks multipass can attach VMs to
+func listNetworks() error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	networks, err := mp.ListNetworks()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(nonNil(networks))
+	}
+
+	if len(networks) == 0 {
+		fmt.Println("No networks found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tDESCRIPTION")
+
+	for _, network := range networks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", network.Name, network.Type, network.Description)
+	}
+
+	w.Flush()
+	return nil
+}
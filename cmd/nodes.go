@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewNodesCmd creates a command to show the nodes of a cluster
+func NewNodesCmd() *cobra.Command {
+	nodesCmd := &cobra.Command{
+		Use:   "nodes <name>",
+		Short: "Show the Kubernetes nodes of a cluster",
+		Long: `Show the Kubernetes nodes of a cluster and the Multipass VM backing each node.
+With -o json, the output of 'kubectl get nodes -o json' is printed as is.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showNodes(args[0])
+		},
+	}
+
+	return nodesCmd
+}
+
+// showNodes prints the nodes of a cluster with the VM each one runs on
+func showNodes(name string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	output, err := k3s.NodesJSON(mp, name)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		fmt.Print(output)
+		return nil
+	}
+
+	nodes, err := k3s.ParseNodes(output)
+	if err != nil {
+		return err
+	}
+
+	vms, err := mp.ListVMs()
+	if err != nil {
+		return fmt.Errorf("failed to list VMs: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tROLES\tVERSION\tINTERNAL-IP\tVM")
+
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Name, node.Status, nodeRoles(node), node.Version, node.InternalIP, nodeVM(node, vms))
+	}
+
+	w.Flush()
+	return nil
+}
+
+// nodeRoles formats the roles of a node like kubectl does
+func nodeRoles(node k3s.Node) string {
+	if len(node.Roles) == 0 {
+		return "<none>"
+	}
+	return strings.Join(node.Roles, ",")
+}
+
+// nodeVM returns the name of the VM a node runs on, matched by IP address, or "-" if unknown
+func nodeVM(node k3s.Node, vms []multipass.VM) string {
+	for _, vm := range vms {
+		if node.InternalIP != "" && (vm.IPv4 == node.InternalIP || vm.IPv6 == node.InternalIP) {
+			return vm.Name
+		}
+	}
+	return "-"
+}
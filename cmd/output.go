@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/rodneyxr/mpkube/pkg/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by the global --output flag
+const (
+	outputText  = "text"
+	outputWide  = "wide"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputYAML  = "yaml"
+)
+
+// outputFormat is the value of the global --output flag
+var outputFormat string
+
+// quiet is the value of the global --quiet flag
+var quiet bool
+
+// verbose is the value of the global --verbose flag
+var verbose bool
+
+// assumeYes is the value of the global --assume-yes flag
+var assumeYes bool
+
+// remoteHost is the value of the global --remote-host flag
+var remoteHost string
+
+// noCache is the value of the global --no-cache flag
+var noCache bool
+
+// multipassTimeout and operationTimeout are the values of the global timeout flags
+var (
+	multipassTimeout time.Duration
+	operationTimeout time.Duration
+)
+
+// validateOutputFormat checks the global --output flag
+func validateOutputFormat() error {
+	switch outputFormat {
+	case "", outputText, outputWide, outputJSON, outputJSONL, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (expected text, wide, json, jsonl or yaml)", outputFormat)
+	}
+}
+
+// jsonOutput reports whether machine-readable JSON output was requested.
+// This includes JSON Lines, which commands without streaming output treat as
+// JSON, and YAML, which printJSON renders from the same values.
+func jsonOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputJSONL || outputFormat == outputYAML
+}
+
+// wideOutput reports whether a wide table was requested. Commands without extra
+// columns treat it as text.
+func wideOutput() bool {
+	return outputFormat == outputWide
+}
+
+// jsonlOutput reports whether JSON Lines output was requested, for commands that
+// stream one JSON object per result as it completes
+func jsonlOutput() bool {
+	return outputFormat == outputJSONL
+}
+
+// newSpinner creates a progress spinner that is disabled for quiet or machine-readable output
+func newSpinner(msg string) *ui.Spinner {
+	return ui.NewSpinner(os.Stdout, msg, !quiet && !jsonOutput())
+}
+
+// infof prints human-readable progress, which is suppressed for machine-readable output
+func infof(format string, a ...any) {
+	if !jsonOutput() {
+		fmt.Printf(format, a...)
+	}
+}
+
+// infoln prints a line of human-readable progress, which is suppressed for machine-readable output
+func infoln(a ...any) {
+	if !jsonOutput() {
+		fmt.Println(a...)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON, on a single line for JSON
+// Lines, or as YAML with the same field names for YAML output
+func printJSON(v any) error {
+	if outputFormat == outputYAML {
+		return printYAML(v)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	if !jsonlOutput() {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
+// printYAML writes v to stdout as YAML. It goes through JSON so the json
+// struct tags name the fields, and decodes into a node to keep their order.
+func printYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles the JSON input gave the nodes,
+// so they are written as regular block YAML
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// errorCode maps an error to a stable code for machine-readable output
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, multipass.ErrVMNotFound):
+		return "ErrVMNotFound"
+	case errors.Is(err, multipass.ErrMultipassNotFound):
+		return "ErrMultipassNotFound"
+	case errors.Is(err, multipass.ErrTimeout):
+		return "ErrTimeout"
+	case errors.Is(err, multipass.ErrInsufficientResources):
+		return "ErrInsufficientResources"
+	default:
+		return "ErrGeneric"
+	}
+}
+
+// exitCodeError carries the exit code of a program run inside a VM so that
+// mpkube can exit with the same code
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withExitCode passes the exit code of a failed program through to mpkube's exit code
+func withExitCode(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return &exitCodeError{err: err, code: exitErr.ExitCode()}
+	}
+	return err
+}
+
+// Exit codes for the failure types scripts commonly branch on
+const (
+	exitGeneric              = 1
+	exitUsage                = 2
+	exitNotFound             = 3
+	exitMultipassUnavailable = 4
+)
+
+// usageError marks an error caused by invalid arguments or flags
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+
+func (e *usageError) Unwrap() error { return e.err }
+
+// markUsageErrors makes flag parsing and argument validation errors of cmd and
+// its subcommands usage errors, so they exit with exitUsage
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &usageError{err}
+	})
+
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			if err := validate(c, args); err != nil {
+				return &usageError{err}
+			}
+			return nil
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// isUsageError reports whether err was caused by invalid arguments or flags.
+// Cobra reports unknown subcommands without a hook, so those are recognized by message.
+func isUsageError(err error) bool {
+	var usageErr *usageError
+	return errors.As(err, &usageErr) || strings.HasPrefix(err.Error(), "unknown command ")
+}
+
+// ExitCode returns the process exit code for err: the exit code of a program
+// run inside a VM, 2 for usage errors, 3 when a cluster or VM is not found,
+// 4 when multipass is unavailable and 1 otherwise
+func ExitCode(err error) int {
+	var codeErr *exitCodeError
+	switch {
+	case errors.As(err, &codeErr):
+		return codeErr.code
+	case isUsageError(err):
+		return exitUsage
+	case errors.Is(err, multipass.ErrVMNotFound):
+		return exitNotFound
+	case errors.Is(err, multipass.ErrMultipassNotFound):
+		return exitMultipassUnavailable
+	default:
+		return exitGeneric
+	}
+}
+
+// PrintError writes err to w in the format selected by --output
+func PrintError(w io.Writer, err error) {
+	if !jsonOutput() {
+		fmt.Fprintln(w, err)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	if encErr := enc.Encode(map[string]string{"error": err.Error(), "code": errorCode(err)}); encErr != nil {
+		fmt.Fprintln(w, err)
+	}
+}
+
+// nonNil returns an empty slice for nil so JSON output renders [] instead of null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewPortForwardCmd creates a command to forward a local port into a cluster
+func NewPortForwardCmd() *cobra.Command {
+	var namespace string
+
+	portForwardCmd := &cobra.Command{
+		Use:   "port-forward <name> [resource] <local>:<remote>",
+		Short: "Forward a local port to a cluster VM or a resource inside it",
+		Long: `Forward 127.0.0.1:<local> on this host into a cluster until interrupted.
+
+Without a resource, connections go to port <remote> on the VM itself. With a
+resource such as svc/web or pod/web-0, kubectl port-forward runs inside the VM
+and connections reach port <remote> of that resource:
+
+  mpkube port-forward mydev 8080:80
+  mpkube port-forward mydev svc/web 8080:80 -n default
+
+Traffic is relayed through multipass exec, so this also works when the VM's
+IP is not reachable from the host, e.g. WSL2 with NAT networking.`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resource := ""
+			if len(args) == 3 {
+				resource = args[1]
+			}
+
+			localPort, remotePort, err := parsePortPair(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+
+			return portForward(args[0], namespace, resource, localPort, remotePort)
+		},
+	}
+
+	portForwardCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the resource")
+
+	return portForwardCmd
+}
+
+// parsePortPair parses a <local>:<remote> port pair. A single port is used for both.
+func parsePortPair(value string) (int, int, error) {
+	local, remote, found := strings.Cut(value, ":")
+	if !found {
+		remote = local
+	}
+
+	localPort, err := parsePort(local)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid local port in %q: %w", value, err)
+	}
+	remotePort, err := parsePort(remote)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid remote port in %q: %w", value, err)
+	}
+	return localPort, remotePort, nil
+}
+
+// parsePort parses a TCP port number
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%q is not a port between 1 and 65535", value)
+	}
+	return port, nil
+}
+
+// portForward forwards localPort on the host to remotePort of the VM, or of a
+// resource inside the cluster, until interrupted
+func portForward(name string, namespace string, resource string, localPort int, remotePort int) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	if resource == "" {
+		return forwardPort(mp, name, localPort, remotePort)
+	}
+
+	// kubectl listens on the VM's loopback interface, on the same port as the host
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- k3s.PortForward(ctx, mp, name, namespace, resource, localPort, remotePort, os.Stderr)
+	}()
+
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		stop()
+		<-errs
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	fmt.Printf("Forwarding %s -> %s %s:%d\n", address, name, resource, remotePort)
+	fmt.Println("Press Ctrl-C to stop.")
+
+	forwardErrs := make(chan error, 1)
+	go func() {
+		forwardErrs <- mp.Forward(ctx, listener, name, localPort)
+	}()
+
+	select {
+	case err := <-errs:
+		// kubectl exited on its own, e.g. the resource does not exist
+		interrupted := ctx.Err() != nil
+		stop()
+		<-forwardErrs
+		if !interrupted && err != nil {
+			return fmt.Errorf("kubectl port-forward failed: %w", err)
+		}
+		return nil
+	case err := <-forwardErrs:
+		stop()
+		<-errs
+		return err
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// errNotInteractive is returned when a confirmation is needed but cannot be
+// asked, because stdin is not a terminal or the output is machine-readable
+var errNotInteractive = errors.New("cannot ask for confirmation")
+
+// Confirm asks a yes/no question, answering yes without prompting when
+// assumeYes is set, e.g. from --assume-yes or a command's --force flag. Every
+// interactive prompt should go through it so they all behave the same way.
+func Confirm(prompt string, assumeYes bool) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if jsonOutput() {
+		return false, fmt.Errorf("%w with --output %s", errNotInteractive, outputFormat)
+	}
+	return readConfirmation(prompt)
+}
+
+// readConfirmation prints prompt and reads a yes/no answer from stdin. Ctrl-C
+// or end of input at the prompt count as no rather than an error.
+func readConfirmation(prompt string) (bool, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("%w: stdin is not a terminal", errNotInteractive)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	type answer struct {
+		input string
+		err   error
+	}
+	answers := make(chan answer, 1)
+
+	fmt.Print(prompt)
+	go func() {
+		input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		answers <- answer{input, err}
+	}()
+
+	select {
+	case <-interrupt:
+		fmt.Println()
+		return false, nil
+	case a := <-answers:
+		if errors.Is(a.err, io.EOF) {
+			fmt.Println()
+			return false, nil
+		}
+		if a.err != nil {
+			return false, fmt.Errorf("failed to read input: %w", a.err)
+		}
+
+		input := strings.TrimSpace(strings.ToLower(a.input))
+		return input == "y" || input == "yes", nil
+	}
+}
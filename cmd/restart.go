@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewRestartCmd creates a command to restart a cluster VM
+func NewRestartCmd() *cobra.Command {
+	var timeout time.Duration
+
+	restartCmd := &cobra.Command{
+		Use:   "restart <name>",
+		Short: "Restart a cluster VM and wait for k3s to come back",
+		Long: `Stop and start the VM of a cluster, then wait until the VM is running and the
+k3s API server is healthy again. The total downtime is reported when done.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restartCluster(args[0], timeout)
+		},
+	}
+
+	restartCmd.Flags().DurationVar(&timeout, "timeout", defaultWaitTimeout, "Maximum time to wait for the cluster to become healthy")
+
+	return restartCmd
+}
+
+// restartCluster stops and starts a cluster VM and waits for k3s to be healthy
+func restartCluster(name string, timeout time.Duration) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	spinner := newSpinner(fmt.Sprintf("Stopping %s...", name))
+	spinner.Start()
+	if err := mp.StopVM(name); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	spinner = newSpinner(fmt.Sprintf("Starting %s...", name))
+	spinner.Start()
+	if err := mp.StartVM(name); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	if err := mp.WaitForState(name, "Running", time.Until(deadline)); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	spinner = newSpinner("Waiting for the API server to become healthy...")
+	spinner.Start()
+	if err := k3s.WaitForAPIHealthy(mp, name, time.Until(deadline)); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	downtime := time.Since(start).Round(time.Second)
+
+	if jsonOutput() {
+		return printJSON(map[string]string{"name": name, "downtime": downtime.String()})
+	}
+
+	fmt.Printf("Cluster %s restarted (downtime: %s)\n", name, downtime)
+	return nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rodneyxr/mpkube/pkg/backup"
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewRestoreCmd creates a command to rebuild a cluster from a backup
+func NewRestoreCmd() *cobra.Command {
+	var from string
+	var writeKubeconfig bool
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [name] --from <backup>",
+		Short: "Rebuild a cluster from a backup",
+		Long: `Create a fresh cluster from a file written by 'mpkube backup', using the
+recorded CPUs, memory, disk, image and k3s version, then restore the k3s
+datastore into it. The name defaults to the name of the backed up cluster,
+which must no longer exist.
+
+The new cluster reuses the backed up server token, which k3s needs to decrypt
+the certificates stored in the datastore. Its kubeconfig is regenerated for the
+new VM's IP once the datastore is restored.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if err := expandPaths(&from); err != nil {
+				return err
+			}
+			return restoreCluster(name, from, writeKubeconfig)
+		},
+	}
+
+	restoreCmd.Flags().StringVar(&from, "from", "", "Backup file written by 'mpkube backup'")
+	restoreCmd.Flags().BoolVar(&writeKubeconfig, "write-kubeconfig", false, "Save the kubeconfig to kubeconfig-<name> in the kubeconfig.dir config directory (default ~/.kube/mpkube)")
+	restoreCmd.MarkFlagRequired("from")
+
+	return restoreCmd
+}
+
+// restoreCluster creates a cluster from the backup at from and restores its datastore
+func restoreCluster(name string, from string, writeKubeconfig bool) error {
+	tmpDir, err := os.MkdirTemp("", "mpkube-restore-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	bundle, err := backup.Read(from, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	token, err := k3s.DataToken(bundle.DataPath)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = bundle.Manifest.Name
+	}
+	name = multipass.ClusterVMName(name)
+
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+	if _, err := mp.GetVMByName(name); err == nil {
+		return fmt.Errorf("cluster '%s' already exists; delete it or restore under another name", name)
+	}
+
+	// Provision with the recorded spec, falling back to the create defaults
+	var md metadata.Cluster
+	if bundle.Metadata != nil {
+		if err := json.Unmarshal(bundle.Metadata, &md); err != nil {
+			return fmt.Errorf("failed to parse metadata in backup: %w", err)
+		}
+	}
+	opts := createOptions{
+		name:    name,
+		cpus:    2,
+		memory:  "2G",
+		disk:    "10G",
+		image:   "22.04",
+		wait:    true,
+		timeout: defaultWaitTimeout,
+		install: k3s.InstallOptions{
+			Version:     bundle.Manifest.K3sVersion,
+			Token:       token,
+			ClusterInit: bundle.Manifest.Datastore == k3s.DatastoreEtcd,
+			DataDir:     md.DataDir,
+		},
+	}
+	if md.CPUs > 0 {
+		opts.cpus = md.CPUs
+	}
+	if md.Memory != "" {
+		opts.memory = md.Memory
+	}
+	if md.Disk != "" {
+		opts.disk = md.Disk
+	}
+	if md.Image != "" {
+		opts.image = md.Image
+	}
+
+	infof("Restoring '%s' from %s (%s datastore, taken %s).\n", bundle.Manifest.Name, from, bundle.Manifest.Datastore, bundle.Manifest.CreatedAt.Format("2006-01-02 15:04"))
+
+	result, _, err := provisionCluster(opts)
+	if err != nil {
+		return err
+	}
+
+	spinner := newSpinner("Restoring the k3s datastore...")
+	spinner.Start()
+	if err := k3s.RestoreData(mp, name, md.DataDir, bundle.Manifest.Datastore, bundle.DataPath); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	if err := k3s.WaitForAPIHealthy(mp, name, defaultWaitTimeout); err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	// The restored datastore carries the original cluster's certificates
+	kubeconfig, err := k3s.GetKubeconfig(mp, name)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	if writeKubeconfig {
+		result.KubeconfigPath, err = defaultKubeconfigPath(name)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(result.KubeconfigPath, []byte(kubeconfig), 0600, false); err != nil {
+			return fmt.Errorf("failed to write kubeconfig: %w", err)
+		}
+	}
+
+	// Carry over the labels of the backed up cluster
+	if len(md.Labels) > 0 {
+		restored, err := metadata.Load(name)
+		if err == nil {
+			restored.Labels = md.Labels
+			err = metadata.Save(restored)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(result)
+	}
+
+	fmt.Printf("\nCluster '%s' restored successfully!\n", name)
+	fmt.Printf("Cluster IP: %s\n", result.IP)
+	if result.KubeconfigPath != "" {
+		fmt.Printf("Kubeconfig saved to: %s\n", result.KubeconfigPath)
+	} else {
+		fmt.Printf("Get its kubeconfig with: mpkube kubeconfig get %s\n", name)
+	}
+	return nil
+}
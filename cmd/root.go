@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/config"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
 	"github.com/spf13/cobra"
 )
 
@@ -14,15 +20,114 @@ func NewRootCmd() *cobra.Command {
 		Short:   "A CLI tool for managing Kubernetes clusters within Multipass",
 		Long:    `mpkube is a command line tool for creating and managing Kubernetes clusters, specifically k3s clusters, within Multipass VMs.`,
 		Version: Version,
+		// Errors are printed by main so they can honor --output
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput() {
+				cmd.SilenceUsage = true
+			}
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			multipass.SetVerbose(verbose)
+			multipass.SetEnvCache(!noCache)
+			multipass.SetTimeouts(multipassTimeout, operationTimeout)
+			if err := applyRemoteHost(); err != nil {
+				return err
+			}
+			return applyClusterPrefix()
+		},
 	}
 
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, wide (extra columns in list), json, jsonl (one JSON object per line, streamed by exec-all and create --from-file) or yaml")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress indicators")
+	rootCmd.PersistentFlags().DurationVar(&multipassTimeout, "multipass-timeout", multipass.DefaultQueryTimeout, "Timeout for quick multipass commands such as list and info (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&operationTimeout, "operation-timeout", multipass.DefaultLongTimeout, "Timeout for long multipass operations such as launch, exec and transfer (0 disables)")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "assume-yes", "y", false, "Answer yes to every confirmation prompt, for scripting")
+	rootCmd.PersistentFlags().StringVar(&remoteHost, "remote-host", "", "Run multipass on another machine over SSH, e.g. user@host (or set MPKUBE_REMOTE_HOST or the remote.host config key)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Detect multipass again instead of using the cached environment (see doctor --refresh)")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Print diagnostic details, such as how multipass was located and its launch output")
+
 	// Add subcommands
 	rootCmd.AddCommand(
 		NewListCmd(),
 		NewCreateCmd(),
 		NewKubeconfigCmd(),
 		NewDeleteCmd(),
+		NewCpCmd(),
+		NewDashboardCmd(),
+		NewConfigCmd(),
+		NewFindCmd(),
+		NewGetIPCmd(),
+		NewDoctorCmd(),
+		NewExecAllCmd(),
+		NewCloneCmd(),
+		NewStartCmd(),
+		NewStopCmd(),
+		NewRestartCmd(),
+		NewHelmCmd(),
+		NewNodesCmd(),
+		NewStatusCmd(),
+		NewSelfUpdateCmd(),
+		NewJoinCmd(),
+		NewTopCmd(),
+		NewCurrentCmd(),
+		NewDiskCmd(),
+		NewEventsCmd(),
+		NewNetworksCmd(),
+		NewTunnelCmd(),
+		NewPortForwardCmd(),
+		NewBackupCmd(),
+		NewRestoreCmd(),
+		NewImagesCmd(),
+		NewDescribeCmd(),
+		NewSwitchCmd(),
 	)
 
+	markUsageErrors(rootCmd)
+
 	return rootCmd
 }
+
+// applyClusterPrefix sets the cluster VM name prefix from MPKUBE_PREFIX or the
+// prefix config key, in that order. The default is mpkube-.
+func applyClusterPrefix() error {
+	prefix := os.Getenv("MPKUBE_PREFIX")
+	if prefix != "" {
+		if err := config.ValidatePrefix(prefix); err != nil {
+			return fmt.Errorf("MPKUBE_PREFIX: %w", err)
+		}
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		prefix = cfg.Prefix
+	}
+
+	multipass.SetClusterPrefix(prefix)
+	return nil
+}
+
+// applyRemoteHost selects the machine multipass runs on from --remote-host,
+// MPKUBE_REMOTE_HOST or the remote.host config key, in that order. The default
+// is the local multipass.
+func applyRemoteHost() error {
+	host := remoteHost
+	if host == "" {
+		host = os.Getenv("MPKUBE_REMOTE_HOST")
+	}
+	if host == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		host = cfg.Remote.Host
+	}
+	if strings.HasPrefix(host, "-") {
+		return fmt.Errorf("invalid remote host %q", host)
+	}
+
+	multipass.SetRemoteHost(host)
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodneyxr/mpkube/pkg/update"
+	"github.com/spf13/cobra"
+)
+
+// updateReport is the result of checking for a newer release
+type updateReport struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"update_available"`
+	Updated         bool   `json:"updated"`
+}
+
+// NewSelfUpdateCmd creates a command to update mpkube to the latest release
+func NewSelfUpdateCmd() *cobra.Command {
+	var checkOnly bool
+
+	selfUpdateCmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update mpkube to the latest release",
+		Long: `Check GitHub for a newer mpkube release and, if there is one, download the
+binary for this OS and architecture, verify its SHA-256 checksum and replace the
+running binary. Use --check-only to only report whether an update is available.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return selfUpdate(checkOnly)
+		},
+	}
+
+	selfUpdateCmd.Flags().BoolVar(&checkOnly, "check-only", false, "Only report whether a newer release is available")
+
+	return selfUpdateCmd
+}
+
+// selfUpdate checks for a newer release and installs it unless checkOnly is set
+func selfUpdate(checkOnly bool) error {
+	spinner := newSpinner("Checking for updates...")
+	spinner.Start()
+	release, err := update.LatestRelease()
+	if err != nil {
+		spinner.Stop("failed")
+		return err
+	}
+	spinner.Stop("done")
+
+	report := updateReport{
+		Current:         Version,
+		Latest:          release.TagName,
+		UpdateAvailable: update.IsNewer(Version, release.TagName),
+	}
+
+	if report.UpdateAvailable && !checkOnly {
+		spinner = newSpinner(fmt.Sprintf("Installing %s...", release.TagName))
+		spinner.Start()
+		if err := update.Apply(release); err != nil {
+			spinner.Stop("failed")
+			return fmt.Errorf("failed to update: %w", err)
+		}
+		spinner.Stop("done")
+		report.Updated = true
+	}
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	switch {
+	case !report.UpdateAvailable:
+		fmt.Printf("mpkube %s is up to date.\n", Version)
+	case report.Updated:
+		fmt.Printf("Updated mpkube from %s to %s.\n", Version, release.TagName)
+	default:
+		fmt.Printf("mpkube %s is available (current: %s). Run 'mpkube self-update' to install it.\n", release.TagName, Version)
+	}
+
+	return nil
+}
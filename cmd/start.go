@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewStartCmd creates a command to start stopped clusters
+func NewStartCmd() *cobra.Command {
+	var all bool
+	var parallel bool
+
+	startCmd := &cobra.Command{
+		Use:   "start [name...]",
+		Short: "Start one or more stopped clusters",
+		Long: `Start the VMs of stopped clusters and their workers, or of every mpkube
+cluster with --all. A failure for one VM does not stop the others, and a
+summary is printed at the end.
+
+With -o json, the started VMs and any errors are printed as
+{"clusters": [...], "errors": [...]}.`,
+		Args: namesOrAll(&all),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runClusterAction(startAction, args, all, parallel)
+		},
+	}
+
+	startCmd.Flags().BoolVar(&all, "all", false, "Start all mpkube clusters")
+	startCmd.Flags().BoolVarP(&parallel, "parallel", "p", false, "Start the clusters concurrently")
+
+	return startCmd
+}
+
+// startAction starts a cluster VM
+var startAction = clusterAction{
+	progress: "Starting",
+	done:     "started",
+	run: func(mp *multipass.MultipassEnv, name string) error {
+		return mp.StartVM(name)
+	},
+}
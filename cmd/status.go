@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// statusReport is the health of a single cluster
+type statusReport struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	IP        string     `json:"ip"`
+	K3sActive bool       `json:"k3s_active"`
+	Version   string     `json:"version"`
+	Nodes     []k3s.Node `json:"nodes"`
+}
+
+// NewStatusCmd creates a command to show the status of a cluster
+func NewStatusCmd() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:   "status <name>",
+		Short: "Show the status of a cluster",
+		Long: `Show the VM state of a cluster, whether the k3s service is active, the
+installed k3s version and the state of its nodes.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showStatus(args[0])
+		},
+	}
+
+	return statusCmd
+}
+
+// showStatus collects and prints the status of a cluster
+func showStatus(name string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	vm, err := mp.GetVMByName(name)
+	if err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	report := clusterStatus(mp, vm)
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	printStatusReport(report)
+	return nil
+}
+
+// clusterStatus collects the health of the cluster running in vm. Details that
+// cannot be read, e.g. while the VM is stopped, are left empty.
+func clusterStatus(mp *multipass.MultipassEnv, vm *multipass.VM) statusReport {
+	report := statusReport{
+		Name:  vm.Name,
+		State: vm.State,
+		IP:    vm.Address(),
+		Nodes: []k3s.Node{},
+	}
+
+	// k3s can only be inspected while the VM is running
+	if strings.EqualFold(vm.State, "Running") {
+		report.K3sActive = k3s.IsActive(mp, vm.Name)
+
+		if version, err := k3s.GetVersion(mp, vm.Name); err == nil {
+			report.Version = version
+		}
+
+		if report.K3sActive {
+			if output, err := k3s.NodesJSON(mp, vm.Name); err == nil {
+				if nodes, err := k3s.ParseNodes(output); err == nil {
+					report.Nodes = nodes
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// printStatusReport prints the report as a table
+func printStatusReport(report statusReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", report.Name)
+	fmt.Fprintf(w, "State:\t%s\n", report.State)
+	fmt.Fprintf(w, "IP:\t%s\n", report.IP)
+	fmt.Fprintf(w, "k3s active:\t%t\n", report.K3sActive)
+	fmt.Fprintf(w, "k3s version:\t%s\n", report.Version)
+	w.Flush()
+
+	printNodeTable(report.Nodes)
+}
+
+// printNodeTable prints nodes under a "Nodes:" heading, or nothing if there are none
+func printNodeTable(nodes []k3s.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	fmt.Println("\nNodes:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tROLES\tVERSION\tINTERNAL-IP")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", node.Name, node.Status, nodeRoles(node), node.Version, node.InternalIP)
+	}
+	w.Flush()
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewStopCmd creates a command to stop running clusters
+func NewStopCmd() *cobra.Command {
+	var all bool
+	var parallel bool
+
+	stopCmd := &cobra.Command{
+		Use:   "stop [name...]",
+		Short: "Stop one or more running clusters",
+		Long: `Stop the VMs of running clusters and their workers, or of every mpkube
+cluster with --all. A failure for one VM does not stop the others, and a
+summary is printed at the end.
+
+With -o json, the stopped VMs and any errors are printed as
+{"clusters": [...], "errors": [...]}.`,
+		Args: namesOrAll(&all),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runClusterAction(stopAction, args, all, parallel)
+		},
+	}
+
+	stopCmd.Flags().BoolVar(&all, "all", false, "Stop all mpkube clusters")
+	stopCmd.Flags().BoolVarP(&parallel, "parallel", "p", false, "Stop the clusters concurrently")
+
+	return stopCmd
+}
+
+// stopAction stops a cluster VM
+var stopAction = clusterAction{
+	progress: "Stopping",
+	done:     "stopped",
+	run: func(mp *multipass.MultipassEnv, name string) error {
+		return mp.StopVM(name)
+	},
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/metadata"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// switchReport is the machine-readable result of a switch
+type switchReport struct {
+	Context string `json:"context"`
+	Merged  bool   `json:"merged"`
+}
+
+// NewSwitchCmd creates a command to point the current kubeconfig context at a cluster
+func NewSwitchCmd() *cobra.Command {
+	var force bool
+	var kubeconfigPath string
+
+	switchCmd := &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Set the current kubeconfig context to a cluster",
+		Long: `Set the current context of a kubeconfig to the mpkube-<name> context, like
+'kubectl config use-context' but aware of the mpkube naming convention.
+
+If the kubeconfig has no context for the cluster yet, the cluster's kubeconfig
+is merged in first after confirmation.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := expandPaths(&kubeconfigPath); err != nil {
+				return err
+			}
+			return switchContext(args[0], kubeconfigPath, force)
+		},
+	}
+
+	switchCmd.Flags().BoolVarP(&force, "force", "f", false, "Merge a missing context without confirmation")
+	switchCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", clientcmd.RecommendedHomeFile, "Kubeconfig file to update")
+
+	return switchCmd
+}
+
+// switchContext sets the current context of the kubeconfig at path to the
+// cluster's context, merging the cluster's kubeconfig in if it is missing
+func switchContext(name string, path string, force bool) error {
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	config, err := clientcmd.LoadFromFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		config = api.NewConfig()
+	} else if err != nil {
+		return fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+
+	merged := false
+	if _, ok := config.Contexts[name]; !ok {
+		if merged, err = mergeClusterContext(config, name, path, force); err != nil {
+			return err
+		}
+		if !merged {
+			fmt.Println("Switch cancelled.")
+			return nil
+		}
+		if _, ok := config.Contexts[name]; !ok {
+			return fmt.Errorf("the kubeconfig of cluster '%s' has no context named %s", name, name)
+		}
+	}
+
+	config.CurrentContext = name
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600, merged); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	if jsonOutput() {
+		return printJSON(switchReport{Context: name, Merged: merged})
+	}
+
+	if merged {
+		fmt.Printf("Merged context \"%s\" into %s.\n", name, path)
+	}
+	fmt.Printf("Switched to context \"%s\".\n", name)
+	return nil
+}
+
+// mergeClusterContext asks to add the cluster's kubeconfig entries to config and
+// reports whether they were added. config is left unchanged if the merge is declined.
+func mergeClusterContext(config *api.Config, name string, path string, force bool) (bool, error) {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return false, fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return false, fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	// Agents have no admin kubeconfig to merge
+	if md, err := metadata.Load(name); err == nil && md.IsAgent() {
+		server := md.Server
+		if server == "" {
+			server = md.ServerURL
+		}
+		return false, fmt.Errorf("'%s' is an agent of %s, not a cluster", name, server)
+	}
+
+	prompt := fmt.Sprintf("Context %s is not in %s. Merge it in? [y/N]: ", name, path)
+	ok, err := Confirm(prompt, force || assumeYes)
+	if errors.Is(err, errNotInteractive) {
+		return false, fmt.Errorf("%w; pass --force or --assume-yes to merge the context without confirmation", err)
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+
+	kubeconfig, err := k3s.GetKubeconfig(mp, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	cluster, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	// The cluster's kubeconfig names its cluster, user and context after the VM
+	for key, value := range cluster.Clusters {
+		config.Clusters[key] = value
+	}
+	for key, value := range cluster.AuthInfos {
+		config.AuthInfos[key] = value
+	}
+	for key, value := range cluster.Contexts {
+		config.Contexts[key] = value
+	}
+	return true, nil
+}
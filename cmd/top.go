@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// errMetricsUnavailable is returned when the cluster has no working metrics API
+var errMetricsUnavailable = errors.New("metrics API not available")
+
+// topNode is the resource usage of a node as reported by kubectl top
+type topNode struct {
+	Name          string `json:"name"`
+	CPU           string `json:"cpu"`
+	CPUPercent    string `json:"cpu_percent"`
+	Memory        string `json:"memory"`
+	MemoryPercent string `json:"memory_percent"`
+}
+
+// topPod is the resource usage of a pod as reported by kubectl top
+type topPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	CPU       string `json:"cpu"`
+	Memory    string `json:"memory"`
+}
+
+// topResult is the machine-readable output of top
+type topResult struct {
+	Nodes []topNode `json:"nodes"`
+	Pods  []topPod  `json:"pods"`
+}
+
+// NewTopCmd creates a command to show resource usage of a cluster
+func NewTopCmd() *cobra.Command {
+	topCmd := &cobra.Command{
+		Use:   "top <name>",
+		Short: "Show node and pod resource usage of a cluster",
+		Long: `Show CPU and memory usage of the nodes and pods of a cluster, as reported by
+'kubectl top'. This requires metrics-server, which k3s deploys by default.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showTop(args[0])
+		},
+	}
+
+	return topCmd
+}
+
+// showTop prints kubectl top output for the nodes and pods of a cluster
+func showTop(name string) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	if jsonOutput() {
+		return printTopJSON(mp, name)
+	}
+
+	nodes, err := kubectlTop(mp, name, "nodes")
+	if err != nil {
+		return err
+	}
+
+	pods, err := kubectlTop(mp, name, "pods", "-A")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(strings.TrimRight(nodes, "\n"))
+	fmt.Println()
+	fmt.Println(strings.TrimRight(pods, "\n"))
+	return nil
+}
+
+// printTopJSON prints the node and pod usage of a cluster as structured output
+func printTopJSON(mp *multipass.MultipassEnv, name string) error {
+	nodes, err := kubectlTop(mp, name, "nodes", "--no-headers")
+	if err != nil {
+		return err
+	}
+
+	pods, err := kubectlTop(mp, name, "pods", "-A", "--no-headers")
+	if err != nil {
+		return err
+	}
+
+	result := topResult{Nodes: []topNode{}, Pods: []topPod{}}
+	for _, line := range strings.Split(nodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		result.Nodes = append(result.Nodes, topNode{
+			Name:          fields[0],
+			CPU:           fields[1],
+			CPUPercent:    fields[2],
+			Memory:        fields[3],
+			MemoryPercent: fields[4],
+		})
+	}
+	for _, line := range strings.Split(pods, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		result.Pods = append(result.Pods, topPod{
+			Namespace: fields[0],
+			Name:      fields[1],
+			CPU:       fields[2],
+			Memory:    fields[3],
+		})
+	}
+
+	return printJSON(result)
+}
+
+// kubectlTop runs kubectl top in the cluster, explaining how to fix a missing metrics API
+func kubectlTop(mp *multipass.MultipassEnv, name string, args ...string) (string, error) {
+	output, err := k3s.Kubectl(mp, name, append([]string{"top"}, args...)...)
+	if err == nil {
+		return output, nil
+	}
+
+	if strings.Contains(output, "Metrics API not available") || strings.Contains(output, "metrics.k8s.io") {
+		return "", fmt.Errorf("%w in %s: metrics-server is missing or not ready yet. It can take a minute after startup; if the cluster was created with metrics-server disabled, recreate it without disabling it", errMetricsUnavailable, name)
+	}
+
+	return "", fmt.Errorf("failed to run kubectl top: %w\n%s", err, output)
+}
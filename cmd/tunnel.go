@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/rodneyxr/mpkube/pkg/k3s"
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"github.com/spf13/cobra"
+)
+
+// NewTunnelCmd creates a command to tunnel the API server of a cluster to localhost
+func NewTunnelCmd() *cobra.Command {
+	var port int
+
+	tunnelCmd := &cobra.Command{
+		Use:   "tunnel <name>",
+		Short: "Forward a cluster's API server to localhost until interrupted",
+		Long: `Forward 127.0.0.1:<port> on this host to the k3s API server of a cluster
+through multipass exec, for hosts that cannot reach the VM's IP directly (such
+as WSL2 with NAT networking). Pair it with a kubeconfig from
+'mpkube kubeconfig get <name> --tunnel':
+
+  mpkube kubeconfig get <name> --tunnel -f ~/.kube/<name>-tunnel
+  mpkube tunnel <name>`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTunnel(args[0], port)
+		},
+	}
+
+	tunnelCmd.Flags().IntVarP(&port, "port", "p", k3s.APIServerPort, "Local port to listen on")
+
+	return tunnelCmd
+}
+
+// runTunnel forwards a local port to the API server of a cluster until interrupted
+func runTunnel(name string, port int) error {
+	mp, err := multipass.NewMultipassEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize multipass environment: %w", err)
+	}
+
+	// Add cluster prefix if not present
+	name = multipass.ClusterVMName(name)
+
+	if _, err := mp.GetVMByName(name); err != nil {
+		return fmt.Errorf("cluster '%s' not found: %w", name, err)
+	}
+
+	return forwardPort(mp, name, port, k3s.APIServerPort)
+}
+
+// forwardPort listens on localPort on the host and relays connections to
+// remotePort inside the VM until interrupted
+func forwardPort(mp *multipass.MultipassEnv, name string, localPort int, remotePort int) error {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Forwarding %s -> %s:%d\n", address, name, remotePort)
+	fmt.Println("Press Ctrl-C to stop.")
+
+	return mp.Forward(ctx, listener, name, remotePort)
+}
+
+// warnRemoteKubeconfig points out that a kubeconfig for a VM on a remote
+// multipass host uses the VM's IP, which is often only reachable from that host
+func warnRemoteKubeconfig(name string) {
+	host := multipass.RemoteHost()
+	if host == "" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Note: %s runs on %s. If its IP is not reachable from here, use 'mpkube kubeconfig get %s --tunnel' with 'mpkube tunnel %s'.\n", name, host, name, name)
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/rodneyxr/mpkube/cmd"
@@ -10,7 +9,7 @@ import (
 func main() {
 	rootCmd := cmd.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		cmd.PrintError(os.Stderr, err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
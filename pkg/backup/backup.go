@@ -0,0 +1,186 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FormatVersion is the version of the backup layout written by Write
+const FormatVersion = 1
+
+// Names of the entries in a backup archive
+const (
+	manifestFile   = "backup.json"
+	kubeconfigFile = "kubeconfig.yaml"
+	metadataFile   = "metadata.json"
+	dataFile       = "k3s-data.tar.gz"
+)
+
+// Manifest describes the cluster a backup was taken from
+type Manifest struct {
+	Version    int       `json:"version"`
+	Name       string    `json:"name"`
+	Datastore  string    `json:"datastore"`
+	K3sVersion string    `json:"k3sVersion,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Bundle is the content of a backup archive. DataPath is a local file holding
+// the archived k3s datastore and server token.
+type Bundle struct {
+	Manifest   Manifest
+	Kubeconfig []byte
+	// Metadata is the cluster's mpkube metadata file, if it had one
+	Metadata []byte
+	DataPath string
+}
+
+// Write saves b as a tar.gz archive at path
+func Write(path string, b *Bundle) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+
+	// The archive holds cluster credentials, so keep it private
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifest},
+		{kubeconfigFile, b.Kubeconfig},
+		{metadataFile, b.Metadata},
+	}
+	for _, entry := range entries {
+		if entry.data == nil {
+			continue
+		}
+		if err := writeEntry(tw, entry.name, int64(len(entry.data)), bytes.NewReader(entry.data)); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.Open(b.DataPath)
+	if err != nil {
+		return fmt.Errorf("failed to read k3s data archive: %w", err)
+	}
+	defer data.Close()
+
+	info, err := data.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read k3s data archive: %w", err)
+	}
+	if err := writeEntry(tw, dataFile, info.Size(), data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return f.Close()
+}
+
+// writeEntry adds a file to the archive
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to backup: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write %s to backup: %w", name, err)
+	}
+	return nil
+}
+
+// Read opens the backup archive at path, extracting the k3s data archive into dir
+func Read(path string, dir string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{}
+	var manifest []byte
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", path, err)
+		}
+
+		switch header.Name {
+		case manifestFile:
+			manifest, err = io.ReadAll(tr)
+		case kubeconfigFile:
+			b.Kubeconfig, err = io.ReadAll(tr)
+		case metadataFile:
+			b.Metadata, err = io.ReadAll(tr)
+		case dataFile:
+			b.DataPath = filepath.Join(dir, dataFile)
+			err = extractFile(tr, b.DataPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from backup: %w", header.Name, err)
+		}
+	}
+
+	if manifest == nil || b.DataPath == "" {
+		return nil, fmt.Errorf("%s is not an mpkube backup: missing %s or %s", path, manifestFile, dataFile)
+	}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse backup manifest: %w", err)
+	}
+	if b.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than this mpkube supports (%d)", b.Manifest.Version, FormatVersion)
+	}
+
+	return b, nil
+}
+
+// extractFile writes the content of r to path
+func extractFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"gopkg.in/yaml.v3"
+)
+
+// ClusterSpec declares a single cluster in a batch file. Fields left out fall
+// back to the flags given on the command line.
+type ClusterSpec struct {
+	Name    string `yaml:"name"`
+	CPUs    int    `yaml:"cpus,omitempty"`
+	Memory  string `yaml:"memory,omitempty"`
+	Disk    string `yaml:"disk,omitempty"`
+	Image   string `yaml:"image,omitempty"`
+	Workers int    `yaml:"workers,omitempty"`
+}
+
+// batchFile is the document read by create --from-file
+type batchFile struct {
+	Clusters []ClusterSpec `yaml:"clusters"`
+}
+
+// clusterNamePattern matches a name multipass accepts for an instance
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z]([-A-Za-z0-9]*[A-Za-z0-9])?$`)
+
+// LoadClusterSpecs reads the clusters declared in a batch file. Unknown keys,
+// such as a misspelled field, are rejected along with invalid values.
+func LoadClusterSpecs(path string) ([]ClusterSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file batchFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(file.Clusters) == 0 {
+		return nil, fmt.Errorf("%s: clusters is required and must declare at least one cluster", path)
+	}
+
+	// Decode again as nodes to report the line of an invalid cluster
+	var lines struct {
+		Clusters []yaml.Node `yaml:"clusters"`
+	}
+	_ = yaml.Unmarshal(data, &lines)
+
+	seen := make(map[string]bool)
+	for i, spec := range file.Clusters {
+		if err := validateClusterSpec(spec, seen); err != nil {
+			if i < len(lines.Clusters) {
+				return nil, fmt.Errorf("%s: clusters[%d] (line %d): %w", path, i, lines.Clusters[i].Line, err)
+			}
+			return nil, fmt.Errorf("%s: clusters[%d]: %w", path, i, err)
+		}
+		seen[multipass.ClusterVMName(spec.Name)] = true
+	}
+
+	return file.Clusters, nil
+}
+
+// validateClusterSpec checks the fields of a cluster declared in a batch file,
+// given the VM names declared before it. Names are compared with the cluster
+// prefix added, so foo and mpkube-foo count as the same cluster.
+func validateClusterSpec(spec ClusterSpec, seen map[string]bool) error {
+	switch {
+	case spec.Name == "":
+		return fmt.Errorf("name is required")
+	case !clusterNamePattern.MatchString(spec.Name):
+		return fmt.Errorf("name %q invalid: must start with a letter and contain only letters, digits and '-'", spec.Name)
+	case seen[multipass.ClusterVMName(spec.Name)]:
+		return fmt.Errorf("duplicate name %q: cluster %s is already declared", spec.Name, multipass.ClusterVMName(spec.Name))
+	case spec.CPUs < 0:
+		return fmt.Errorf("cpus invalid: %d is negative", spec.CPUs)
+	case spec.Workers < 0:
+		return fmt.Errorf("workers invalid: %d is negative", spec.Workers)
+	}
+
+	if spec.Memory != "" {
+		if _, err := NormalizeSize(spec.Memory); err != nil {
+			return fmt.Errorf("memory invalid: %w", err)
+		}
+	}
+	if spec.Disk != "" {
+		if _, err := NormalizeSize(spec.Disk); err != nil {
+			return fmt.Errorf("disk invalid: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds user defaults stored in ~/.mpkube/config.yaml
+type Config struct {
+	// Prefix is the VM name prefix that marks a VM as an mpkube cluster
+	Prefix string         `yaml:"prefix,omitempty"`
+	Create CreateDefaults `yaml:"create,omitempty"`
+	// Kubeconfig holds where kubeconfig files are written
+	Kubeconfig KubeconfigSettings `yaml:"kubeconfig,omitempty"`
+	// Remote holds settings for driving multipass on another machine
+	Remote RemoteSettings `yaml:"remote,omitempty"`
+}
+
+// RemoteSettings holds settings for running multipass on a remote host over SSH
+type RemoteSettings struct {
+	// Host is the SSH destination, e.g. user@host; empty uses local multipass
+	Host string `yaml:"host,omitempty"`
+}
+
+// KubeconfigSettings holds settings for kubeconfig files written by mpkube
+type KubeconfigSettings struct {
+	// Dir is the directory kubeconfigs are saved in; ~ and environment variables are expanded
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// CreateDefaults holds defaults for the create command
+type CreateDefaults struct {
+	CPUs   int    `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+	Disk   string `yaml:"disk,omitempty"`
+}
+
+// key describes how to read and write a single config setting
+type key struct {
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// sizePattern matches a size with a unit, e.g. 512M, 2g, 2GB or 2GiB
+var sizePattern = regexp.MustCompile(`^(?i)([0-9]+)\s*([KMG])(?:i?B)?$`)
+
+// prefixPattern matches a valid VM name prefix
+var prefixPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*$`)
+
+// keys lists every supported config setting
+var keys = map[string]key{
+	"prefix": {
+		get: func(c *Config) string { return c.Prefix },
+		set: func(c *Config, value string) error {
+			if err := ValidatePrefix(value); err != nil {
+				return err
+			}
+			c.Prefix = value
+			return nil
+		},
+	},
+	"create.cpus": {
+		get: func(c *Config) string {
+			if c.Create.CPUs == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.Create.CPUs)
+		},
+		set: func(c *Config, value string) error {
+			cpus, err := strconv.Atoi(value)
+			if err != nil || cpus < 1 {
+				return fmt.Errorf("create.cpus must be a positive integer")
+			}
+			c.Create.CPUs = cpus
+			return nil
+		},
+	},
+	"create.memory": {
+		get: func(c *Config) string { return c.Create.Memory },
+		set: func(c *Config, value string) error {
+			size, err := NormalizeSize(value)
+			if err != nil {
+				return fmt.Errorf("create.memory: %w", err)
+			}
+			c.Create.Memory = size
+			return nil
+		},
+	},
+	"kubeconfig.dir": {
+		get: func(c *Config) string { return c.Kubeconfig.Dir },
+		set: func(c *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("kubeconfig.dir must not be empty")
+			}
+			c.Kubeconfig.Dir = value
+			return nil
+		},
+	},
+	"remote.host": {
+		get: func(c *Config) string { return c.Remote.Host },
+		set: func(c *Config, value string) error {
+			if strings.HasPrefix(value, "-") || strings.ContainsAny(value, " \t") {
+				return fmt.Errorf("remote.host must be an SSH destination such as user@host")
+			}
+			c.Remote.Host = value
+			return nil
+		},
+	},
+	"create.disk": {
+		get: func(c *Config) string { return c.Create.Disk },
+		set: func(c *Config, value string) error {
+			size, err := NormalizeSize(value)
+			if err != nil {
+				return fmt.Errorf("create.disk: %w", err)
+			}
+			c.Create.Disk = size
+			return nil
+		},
+	},
+}
+
+// ValidatePrefix checks that prefix can start a multipass VM name
+func ValidatePrefix(prefix string) error {
+	if !prefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid prefix %q (must start with a letter and contain only letters, digits and hyphens)", prefix)
+	}
+	return nil
+}
+
+// NormalizeSize converts a size such as 2g, 2GB or 2GiB into the form multipass
+// accepts consistently across versions, e.g. 2G. A unit is required, since a
+// bare number such as 2048 would be taken as bytes.
+func NormalizeSize(value string) (string, error) {
+	match := sizePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", fmt.Errorf("invalid size %q (expected a number with a K, M or G unit, e.g. 512M or 2G; bare numbers are not accepted)", value)
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n == 0 {
+		return "", fmt.Errorf("invalid size %q: must be greater than zero", value)
+	}
+
+	return strconv.Itoa(n) + strings.ToUpper(match[2]), nil
+}
+
+// ResolveCPUs converts a CPU count, a percentage of the host CPUs such as 50%
+// or "max" into a number of CPUs. Percentages round down but must leave at least 1.
+func ResolveCPUs(value string, hostCPUs int) (int, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.EqualFold(value, "max") {
+		return hostCPUs, nil
+	}
+
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		p, err := strconv.Atoi(strings.TrimSpace(percent))
+		if err != nil || p < 1 || p > 100 {
+			return 0, fmt.Errorf("invalid CPU count %q (percentages must be between 1%% and 100%%)", value)
+		}
+		cpus := hostCPUs * p / 100
+		if cpus < 1 {
+			return 0, fmt.Errorf("%s of %d host CPUs is less than 1 CPU", value, hostCPUs)
+		}
+		return cpus, nil
+	}
+
+	cpus, err := strconv.Atoi(value)
+	if err != nil || cpus < 1 {
+		return 0, fmt.Errorf("invalid CPU count %q (expected a positive integer, a percentage such as 50%% or max)", value)
+	}
+	return cpus, nil
+}
+
+// IsRelativeCPUs reports whether a CPU value is a percentage or "max", which
+// ResolveCPUs resolves against the number of host CPUs
+func IsRelativeCPUs(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.EqualFold(value, "max") || strings.HasSuffix(value, "%")
+}
+
+// ExpandPath expands a leading ~ to the home directory and $VAR or ${VAR}
+// references to the values of environment variables
+func ExpandPath(path string) (string, error) {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	return path, nil
+}
+
+// KubeconfigDir returns the expanded directory kubeconfigs are saved in,
+// defaulting to ~/.kube/mpkube
+func (c *Config) KubeconfigDir() (string, error) {
+	dir := c.Kubeconfig.Dir
+	if dir == "" {
+		dir = filepath.Join("~", ".kube", "mpkube")
+	}
+	return ExpandPath(dir)
+}
+
+// SizeBytes returns the number of bytes in a size accepted by NormalizeSize
+func SizeBytes(value string) (int64, error) {
+	size, err := NormalizeSize(value)
+	if err != nil {
+		return 0, err
+	}
+
+	var unit int64
+	switch size[len(size)-1] {
+	case 'K':
+		unit = 1 << 10
+	case 'M':
+		unit = 1 << 20
+	case 'G':
+		unit = 1 << 30
+	}
+
+	n, err := strconv.ParseInt(size[:len(size)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", value, err)
+	}
+	return n * unit, nil
+}
+
+// Keys returns the supported config keys in sorted order
+func Keys() []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Path returns the location of the config file
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".mpkube", "config.yaml"), nil
+}
+
+// Load reads the config file, returning an empty config if it does not exist
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the config file, creating its directory if needed
+func (c *Config) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := c.YAML()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// YAML renders the config as YAML
+func (c *Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to render config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Get returns the value of a config key, or "" if it is unset
+func (c *Config) Get(name string) (string, error) {
+	k, ok := keys[name]
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", name)
+	}
+	return k.get(c), nil
+}
+
+// Set validates and stores the value of a config key
+func (c *Config) Set(name string, value string) error {
+	k, ok := keys[name]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", name)
+	}
+	return k.set(c, value)
+}
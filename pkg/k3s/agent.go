@@ -0,0 +1,71 @@
+package k3s
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// nodeTokenPath is where the k3s server stores the token agents join with,
+// relative to the k3s data directory
+const nodeTokenPath = "server/node-token"
+
+// GetNodeToken returns the token agents use to join the k3s server in the VM.
+// dataDir is the server's k3s data directory, or "" for the default.
+func GetNodeToken(mp *multipass.MultipassEnv, vmName string, dataDir string) (string, error) {
+	output, err := mp.Exec(vmName, "sudo", "cat", dataDirOrDefault(dataDir)+"/"+nodeTokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read node token: %w\n%s", err, output)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// ServerURL returns the URL agents use to reach the k3s server in the VM
+func ServerURL(vm *multipass.VM) string {
+	return "https://" + net.JoinHostPort(vm.Address(), strconv.Itoa(APIServerPort))
+}
+
+// InstallAgent installs k3s in agent mode in the VM and joins it to the server at serverURL
+func InstallAgent(mp *multipass.MultipassEnv, vmName string, serverURL string, token string, opts InstallOptions) error {
+	vm, err := mp.GetVMByName(vmName)
+	if err != nil {
+		return err
+	}
+
+	nodeIP := vm.Address()
+	if opts.NodeIP != "" {
+		nodeIP = opts.NodeIP
+	}
+
+	installExec := "agent --node-ip=" + nodeIP
+	if opts.NodeName != "" {
+		installExec += " --node-name=" + opts.NodeName
+	}
+
+	// k3s only reads registries.yaml at startup, so it must exist before install
+	if hasRegistryConfig(opts) {
+		if err := writeRegistriesConfig(mp, vmName, opts); err != nil {
+			return err
+		}
+	}
+
+	// The service env file must be in place before k3s first starts
+	if hasProxy(opts) {
+		if err := writeProxyEnv(mp, vmName, agentEnvPath, opts); err != nil {
+			return err
+		}
+	}
+
+	installCmd := fmt.Sprintf(
+		"%scurl -sSfL https://get.k3s.io | %sK3S_URL=%s K3S_TOKEN=%s INSTALL_K3S_EXEC=\"%s\" sh -",
+		proxyExports(opts), versionEnv(opts), shellQuote(serverURL), shellQuote(token), installExec,
+	)
+
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", installCmd); err != nil {
+		return installError(err, output)
+	}
+	return nil
+}
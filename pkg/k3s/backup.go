@@ -0,0 +1,155 @@
+package k3s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// Datastores k3s can keep cluster state in
+const (
+	DatastoreSQLite = "sqlite"
+	DatastoreEtcd   = "etcd"
+)
+
+// serverDir returns the directory holding the k3s server state, including the
+// datastore and token, for a data directory ("" for the default)
+func serverDir(dataDir string) string {
+	return dataDirOrDefault(dataDir) + "/server"
+}
+
+const (
+	// dataArchivePath is where the datastore archive is built inside the VM
+	dataArchivePath = "/tmp/mpkube-k3s-data.tar.gz"
+	// dataStagingDir is a scratch directory used while building the archive
+	dataStagingDir = "/tmp/mpkube-k3s-data"
+)
+
+// Datastore reports whether the cluster keeps its state in embedded etcd or sqlite.
+// dataDir is the k3s data directory, or "" for the default.
+func Datastore(mp *multipass.MultipassEnv, vmName string, dataDir string) (string, error) {
+	output, err := mp.Exec(vmName, "sudo", "test", "-d", serverDir(dataDir)+"/db/etcd")
+	if err == nil {
+		return DatastoreEtcd, nil
+	}
+	if _, statErr := mp.Exec(vmName, "sudo", "test", "-d", serverDir(dataDir)+"/db"); statErr != nil {
+		return "", fmt.Errorf("no k3s datastore found in %s: %w\n%s", vmName, err, output)
+	}
+	return DatastoreSQLite, nil
+}
+
+// BackupData archives the cluster datastore and server token into a tar.gz and
+// copies it to localPath. Etcd clusters are snapshotted with k3s etcd-snapshot
+// while running; sqlite clusters have k3s stopped briefly so the database is
+// consistent. It returns the datastore type.
+func BackupData(mp *multipass.MultipassEnv, vmName string, dataDir string, localPath string) (string, error) {
+	datastore, err := Datastore(mp, vmName, dataDir)
+	if err != nil {
+		return "", err
+	}
+
+	var script string
+	switch datastore {
+	case DatastoreEtcd:
+		script = fmt.Sprintf(`set -e
+rm -rf %[1]s && mkdir -p %[1]s/snapshot
+k3s etcd-snapshot save --data-dir %[4]s --dir %[1]s/snapshot --name mpkube >/dev/null
+cp %[2]s/token %[1]s/token
+tar -C %[1]s -czf %[3]s snapshot token
+rm -rf %[1]s`, dataStagingDir, serverDir(dataDir), dataArchivePath, dataDirOrDefault(dataDir))
+	default:
+		script = fmt.Sprintf(`set -e
+trap 'systemctl start k3s' EXIT
+systemctl stop k3s
+tar -C %[1]s -czf %[2]s db token`, serverDir(dataDir), dataArchivePath)
+	}
+	// The archive holds the server token, so only the default user may read it
+	script += fmt.Sprintf("\nchown \"$SUDO_USER\" %[1]s && chmod 0600 %[1]s", dataArchivePath)
+
+	if output, err := mp.Exec(vmName, "sudo", "bash", "-c", script); err != nil {
+		return "", fmt.Errorf("failed to archive the %s datastore: %w\n%s", datastore, err, output)
+	}
+	defer mp.Exec(vmName, "sudo", "rm", "-f", dataArchivePath)
+
+	if err := mp.CopyFromVM(vmName, dataArchivePath, localPath); err != nil {
+		return "", err
+	}
+
+	return datastore, nil
+}
+
+// DataToken returns the server token stored in a datastore archive written by BackupData
+func DataToken(localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open k3s data archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read k3s data archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("k3s data archive has no server token")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read k3s data archive: %w", err)
+		}
+
+		if strings.TrimPrefix(header.Name, "./") == "token" {
+			token, err := io.ReadAll(tr)
+			if err != nil {
+				return "", fmt.Errorf("failed to read server token: %w", err)
+			}
+			return strings.TrimSpace(string(token)), nil
+		}
+	}
+}
+
+// RestoreData replaces the datastore of a freshly installed cluster with one
+// archived by BackupData and restarts k3s. The cluster must have been installed
+// with the backed up token and, for etcd, with ClusterInit. dataDir is the k3s
+// data directory, or "" for the default.
+func RestoreData(mp *multipass.MultipassEnv, vmName string, dataDir string, datastore string, localPath string) error {
+	if err := mp.CopyToVM(localPath, vmName, dataArchivePath); err != nil {
+		return err
+	}
+	defer mp.Exec(vmName, "sudo", "rm", "-rf", dataArchivePath, dataStagingDir)
+
+	var script string
+	switch datastore {
+	case DatastoreEtcd:
+		script = fmt.Sprintf(`set -e
+rm -rf %[1]s && mkdir -p %[1]s
+tar -C %[1]s -xzf %[2]s snapshot
+snapshot=$(ls %[1]s/snapshot/* | head -n 1)
+systemctl stop k3s
+k3s server --data-dir %[3]s --cluster-reset --cluster-reset-restore-path="$snapshot"
+systemctl start k3s`, dataStagingDir, dataArchivePath, dataDirOrDefault(dataDir))
+	case DatastoreSQLite:
+		script = fmt.Sprintf(`set -e
+systemctl stop k3s
+rm -rf %[1]s/db
+tar -C %[1]s -xzf %[2]s db
+systemctl start k3s`, serverDir(dataDir), dataArchivePath)
+	default:
+		return fmt.Errorf("unsupported datastore %q", datastore)
+	}
+
+	if output, err := mp.Exec(vmName, "sudo", "bash", "-c", script); err != nil {
+		return fmt.Errorf("failed to restore the %s datastore: %w\n%s", datastore, err, outputTail(output))
+	}
+	return nil
+}
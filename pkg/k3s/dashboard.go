@@ -0,0 +1,51 @@
+package k3s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+const (
+	// dashboardManifestURL is the upstream Kubernetes dashboard manifest
+	dashboardManifestURL = "https://raw.githubusercontent.com/kubernetes/dashboard/v2.7.0/aio/deploy/recommended.yaml"
+	// DashboardNamespace is the namespace the dashboard is installed into
+	DashboardNamespace = "kubernetes-dashboard"
+	// dashboardAdminUser is the service account used to log in to the dashboard
+	dashboardAdminUser = "admin-user"
+)
+
+// InstallDashboard deploys the Kubernetes dashboard and an admin service account.
+// Every step uses apply semantics so it is safe to run on a cluster that already has it.
+func InstallDashboard(mp *multipass.MultipassEnv, vmName string) error {
+	if output, err := Kubectl(mp, vmName, "apply", "-f", dashboardManifestURL); err != nil {
+		return fmt.Errorf("failed to apply dashboard manifests: %w\n%s", err, output)
+	}
+
+	// Render the admin account with --dry-run and pipe it to apply so re-runs don't fail on AlreadyExists
+	adminCmds := []string{
+		fmt.Sprintf("sudo k3s kubectl -n %s create serviceaccount %s --dry-run=client -o yaml | sudo k3s kubectl apply -f -",
+			DashboardNamespace, dashboardAdminUser),
+		fmt.Sprintf("sudo k3s kubectl create clusterrolebinding %s --clusterrole=cluster-admin --serviceaccount=%s:%s --dry-run=client -o yaml | sudo k3s kubectl apply -f -",
+			dashboardAdminUser, DashboardNamespace, dashboardAdminUser),
+	}
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", strings.Join(adminCmds, " && ")); err != nil {
+		return fmt.Errorf("failed to create dashboard admin user: %w\n%s", err, output)
+	}
+
+	if output, err := Kubectl(mp, vmName, "-n", DashboardNamespace, "rollout", "status", "deployment/kubernetes-dashboard", "--timeout=5m"); err != nil {
+		return fmt.Errorf("dashboard did not become available: %w\n%s", err, output)
+	}
+
+	return nil
+}
+
+// DashboardToken creates a login token for the dashboard admin user
+func DashboardToken(mp *multipass.MultipassEnv, vmName string) (string, error) {
+	output, err := Kubectl(mp, vmName, "-n", DashboardNamespace, "create", "token", dashboardAdminUser)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dashboard token: %w\n%s", err, output)
+	}
+	return strings.TrimSpace(output), nil
+}
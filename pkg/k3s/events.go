@@ -0,0 +1,94 @@
+package k3s
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// Event is the summary of a Kubernetes event shown by mpkube
+type Event struct {
+	Namespace string    `json:"namespace"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Object    string    `json:"object"`
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+}
+
+// eventList mirrors the parts of kubectl get events -o json used by mpkube
+type eventList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace         string    `json:"namespace"`
+			CreationTimestamp time.Time `json:"creationTimestamp"`
+		} `json:"metadata"`
+		InvolvedObject struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"involvedObject"`
+		Type          string     `json:"type"`
+		Reason        string     `json:"reason"`
+		Message       string     `json:"message"`
+		Count         int        `json:"count"`
+		LastTimestamp *time.Time `json:"lastTimestamp"`
+		EventTime     *time.Time `json:"eventTime"`
+	} `json:"items"`
+}
+
+// eventsArgs are the kubectl arguments that list events in every namespace, oldest first
+var eventsArgs = []string{"get", "events", "-A", "--sort-by=.lastTimestamp"}
+
+// EventsJSON returns the output of kubectl get events -A -o json for the cluster
+func EventsJSON(mp *multipass.MultipassEnv, vmName string) (string, error) {
+	output, err := Kubectl(mp, vmName, append(eventsArgs, "-o", "json")...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get events: %w\n%s", err, output)
+	}
+	return output, nil
+}
+
+// WatchEvents streams events of the cluster to the terminal until interrupted
+func WatchEvents(mp *multipass.MultipassEnv, vmName string) error {
+	return KubectlInteractive(mp, vmName, append(eventsArgs, "--watch")...)
+}
+
+// ParseEvents parses the output of kubectl get events -o json, sorted oldest first.
+// Events without a lastTimestamp fall back to their eventTime or creation time.
+func ParseEvents(output string) ([]Event, error) {
+	var list eventList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse events: %w", err)
+	}
+
+	events := make([]Event, 0, len(list.Items))
+	for _, item := range list.Items {
+		event := Event{
+			Namespace: item.Metadata.Namespace,
+			LastSeen:  item.Metadata.CreationTimestamp,
+			Type:      item.Type,
+			Reason:    item.Reason,
+			Object:    item.InvolvedObject.Kind + "/" + item.InvolvedObject.Name,
+			Message:   item.Message,
+			Count:     item.Count,
+		}
+		switch {
+		case item.LastTimestamp != nil && !item.LastTimestamp.IsZero():
+			event.LastSeen = *item.LastTimestamp
+		case item.EventTime != nil && !item.EventTime.IsZero():
+			event.LastSeen = *item.EventTime
+		}
+
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.Before(events[j].LastSeen)
+	})
+
+	return events, nil
+}
@@ -0,0 +1,30 @@
+package k3s
+
+import (
+	"fmt"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// helmInstallScript is the official script that installs the latest Helm 3 release
+const helmInstallScript = "https://raw.githubusercontent.com/helm/helm/main/scripts/get-helm-3"
+
+// EnsureHelm installs Helm in the VM unless it is already available
+func EnsureHelm(mp *multipass.MultipassEnv, vmName string) error {
+	if _, err := mp.Exec(vmName, "bash", "-c", "command -v helm"); err == nil {
+		return nil
+	}
+
+	installCmd := fmt.Sprintf("curl -fsSL %s | bash", helmInstallScript)
+	if output, err := mp.Exec(vmName, "bash", "-c", installCmd); err != nil {
+		return fmt.Errorf("failed to install helm: %w\n%s", err, output)
+	}
+
+	return nil
+}
+
+// HelmInteractive runs helm in the VM against the k3s cluster, streaming its output
+func HelmInteractive(mp *multipass.MultipassEnv, vmName string, args ...string) error {
+	execArgs := append([]string{"exec", vmName, "--", "sudo", "env", "KUBECONFIG=" + kubeconfigPath, "helm"}, args...)
+	return mp.RunMultipassCmdInteractive(execArgs...)
+}
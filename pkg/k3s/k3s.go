@@ -1,58 +1,597 @@
 package k3s
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+// ErrK3sNotInitialized is returned when k3s has not finished its first start
+var ErrK3sNotInitialized = errors.New("k3s not yet initialized")
+
+// APIServerPort is the port the k3s API server listens on
+const APIServerPort = 6443
+
+// ErrSudoPassword is returned when sudo inside a VM asks for a password
+var ErrSudoPassword = errors.New("sudo requires a password")
+
+const (
+	// kubeconfigPath is where k3s writes the admin kubeconfig
+	kubeconfigPath = "/etc/rancher/k3s/k3s.yaml"
+	// kubeconfigAttempts is how many times a missing kubeconfig is read before giving up
+	kubeconfigAttempts = 5
+	// kubeconfigRetryDelay is the delay between attempts to read a missing kubeconfig
+	kubeconfigRetryDelay = 3 * time.Second
+	// kubeconfigReadTimeout bounds a single attempt to read the kubeconfig
+	kubeconfigReadTimeout = time.Minute
+)
+
+// DefaultDataDir is where k3s keeps its state unless --data-dir is given
+const DefaultDataDir = "/var/lib/rancher/k3s"
+
+// dataDirPattern matches an absolute VM path that is safe to pass in the install arguments
+var dataDirPattern = regexp.MustCompile(`^/[A-Za-z0-9._/-]+$`)
+
+// ValidateDataDir checks that dir can be used as the k3s data directory
+func ValidateDataDir(dir string) error {
+	if !dataDirPattern.MatchString(dir) || path.Clean(dir) == "/" {
+		return fmt.Errorf("invalid data directory %q (must be an absolute path below / containing only letters, digits, '.', '_', '-' and '/')", dir)
+	}
+	return nil
+}
+
+// dataDirOrDefault returns dataDir, or DefaultDataDir if it is empty
+func dataDirOrDefault(dataDir string) string {
+	if dataDir == "" {
+		return DefaultDataDir
+	}
+	return path.Clean(dataDir)
+}
+
+// InstallOptions configures how k3s is installed on a VM
+type InstallOptions struct {
+	// Version is the k3s release to install; empty installs the latest stable release
+	Version string
+	// Channel is the release channel to track, e.g. stable, latest or testing; exclusive with Version
+	Channel string
+	// AirGapped installs k3s from local artifacts instead of downloading them in the VM
+	AirGapped bool
+	// BinaryPath is the local path to the k3s binary (air-gapped only)
+	BinaryPath string
+	// ImagesPath is the local path to the k3s airgap images tarball (air-gapped only, optional)
+	ImagesPath string
+	// InstallScriptPath is the local path to the k3s install script (air-gapped only)
+	InstallScriptPath string
+	// AdvertiseAddress is the address the API server advertises; defaults to the VM's IP
+	AdvertiseAddress string
+	// NodeIP is the node's internal address; defaults to the VM's IP
+	NodeIP string
+	// NodeName is the Kubernetes node name; defaults to the VM's hostname
+	NodeName string
+	// TaintServer keeps regular workloads off the server so they only run on workers
+	TaintServer bool
+	// ClusterCIDR is the pod network range (k3s default 10.42.0.0/16)
+	ClusterCIDR string
+	// ServiceCIDR is the service network range (k3s default 10.43.0.0/16)
+	ServiceCIDR string
+	// RegistryAuth maps private registry hosts to their credentials
+	RegistryAuth map[string]RegistryAuth
+	// RegistryInsecure lists registry hosts whose TLS certificates are not verified
+	RegistryInsecure []string
+	// HTTPProxy, HTTPSProxy and NoProxy configure a proxy for the install and for containerd
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	// ResolvConfPath is a local resolv.conf whose upstream resolvers CoreDNS should use
+	ResolvConfPath string
+	// Token is the server token; empty lets k3s generate one. Restores reuse the
+	// backed up token, which encrypts the bootstrap data in the datastore.
+	Token string
+	// ClusterInit uses embedded etcd instead of sqlite as the datastore
+	ClusterInit bool
+	// DataDir is where k3s keeps its state inside the VM; empty uses DefaultDataDir
+	DataDir string
+}
+
 // InstallK3s installs K3s on a multipass VM without traefik
-func InstallK3s(mp *multipass.MultipassEnv, vmName string) error {
+func InstallK3s(mp *multipass.MultipassEnv, vmName string, opts InstallOptions) error {
 	vm, err := mp.GetVMByName(vmName)
 	if err != nil {
 		return err
 	}
 
-	// Prepare the K3s install command with traefik disabled and advertise the VM's IP
+	if err := validateVMAddresses(mp, vmName, opts); err != nil {
+		return err
+	}
+
+	installExec := strings.Join(serverArgs(vm, opts), " ")
+
+	// The data directory may be a fresh mount point, so create it before k3s starts
+	if opts.DataDir != "" {
+		if output, err := mp.Exec(vmName, "sudo", "mkdir", "-p", opts.DataDir); err != nil {
+			return fmt.Errorf("failed to create data directory %s: %w\n%s", opts.DataDir, err, output)
+		}
+	}
+
+	// k3s only reads registries.yaml at startup, so it must exist before install
+	if hasRegistryConfig(opts) {
+		if err := writeRegistriesConfig(mp, vmName, opts); err != nil {
+			return err
+		}
+	}
+
+	// The service env file must be in place before k3s first starts
+	if hasProxy(opts) {
+		if err := writeProxyEnv(mp, vmName, serverEnvPath, opts); err != nil {
+			return err
+		}
+	}
+
+	// k3s hands the resolv.conf to the kubelet, so it must exist before k3s starts
+	if opts.ResolvConfPath != "" {
+		if err := writeResolvConf(mp, vmName, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.AirGapped {
+		return installK3sAirGapped(mp, vmName, installExec, opts)
+	}
+
+	// Prepare the K3s install command
 	k3sInstallCmd := fmt.Sprintf(
-		"curl -sfL https://get.k3s.io | INSTALL_K3S_EXEC=\"--disable=traefik --advertise-address=%s --node-ip=%s\" sh -",
-		vm.IPv4, vm.IPv4,
+		"%scurl -sSfL https://get.k3s.io | %s%sINSTALL_K3S_EXEC=\"%s\" sh -",
+		proxyExports(opts), versionEnv(opts), tokenEnv(opts), installExec,
 	)
 
 	// Execute the command through multipass, which will handle WSL/Windows integration
-	_, err = mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", k3sInstallCmd)
-	return err
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", k3sInstallCmd); err != nil {
+		return installError(err, output)
+	}
+	return nil
+}
+
+// installOutputLines is how many trailing lines of installer or script output are kept in errors
+const installOutputLines = 20
+
+// installError wraps a failed installer run with the end of its output, which
+// usually says why the install failed (e.g. a network error or unsupported kernel)
+func installError(err error, output string) error {
+	if tail := outputTail(output); tail != "" {
+		return fmt.Errorf("installer failed: %w\nInstaller output:\n%s", err, tail)
+	}
+	return fmt.Errorf("installer failed: %w (no output)", err)
+}
+
+// outputTail returns the last installOutputLines lines of output
+func outputTail(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > installOutputLines {
+		lines = append([]string{"..."}, lines[len(lines)-installOutputLines:]...)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// versionEnv returns the installer environment selecting the k3s release, or "" for the default
+func versionEnv(opts InstallOptions) string {
+	if opts.Version != "" {
+		return "INSTALL_K3S_VERSION=" + shellQuote(opts.Version) + " "
+	}
+	if opts.Channel != "" {
+		return "INSTALL_K3S_CHANNEL=" + shellQuote(opts.Channel) + " "
+	}
+	return ""
+}
+
+// tokenEnv returns the installer environment setting the server token, or "" to generate one
+func tokenEnv(opts InstallOptions) string {
+	if opts.Token == "" {
+		return ""
+	}
+	return "K3S_TOKEN=" + shellQuote(opts.Token) + " "
+}
+
+// GetVersion returns the version of k3s installed in the VM, e.g. v1.30.4+k3s1
+func GetVersion(mp *multipass.MultipassEnv, vmName string) (string, error) {
+	output, err := mp.RunMultipassCmd("exec", vmName, "--", "k3s", "--version")
+	if err != nil {
+		return "", fmt.Errorf("failed to get k3s version: %w\n%s", err, output)
+	}
+
+	// Output looks like "k3s version v1.30.4+k3s1 (98262b5d)"
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[1] != "version" {
+		return "", fmt.Errorf("unexpected k3s version output: %s", strings.TrimSpace(output))
+	}
+
+	return fields[2], nil
+}
+
+// validateVMAddresses checks that overridden addresses are assigned to the VM,
+// which matters in bridged or multi-NIC setups where the VM has several IPs
+func validateVMAddresses(mp *multipass.MultipassEnv, vmName string, opts InstallOptions) error {
+	if opts.AdvertiseAddress == "" && opts.NodeIP == "" {
+		return nil
+	}
+
+	info, err := mp.GetVMInfo(vmName)
+	if err != nil {
+		return err
+	}
+
+	// multipass info only reports IPv4, so add the IPv6 address from the VM list
+	addresses := info.IPv4
+	if vm, err := mp.GetVMByName(vmName); err == nil && net.ParseIP(vm.IPv6) != nil {
+		addresses = append(addresses, vm.IPv6)
+	}
+
+	if opts.AdvertiseAddress != "" && !slices.Contains(addresses, opts.AdvertiseAddress) {
+		return fmt.Errorf("advertise address %s is not assigned to %s (VM addresses: %s)", opts.AdvertiseAddress, vmName, strings.Join(addresses, ", "))
+	}
+
+	// --node-ip takes one address per family on dual-stack clusters
+	if opts.NodeIP != "" {
+		for _, ip := range strings.Split(opts.NodeIP, ",") {
+			if !slices.Contains(addresses, ip) {
+				return fmt.Errorf("node IP %s is not assigned to %s (VM addresses: %s)", ip, vmName, strings.Join(addresses, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// serverTaint is the taint that reserves the server for critical add-ons, as
+// recommended by k3s for dedicated control-plane nodes
+const serverTaint = "CriticalAddonsOnly=true:NoExecute"
+
+// serverArgs returns the k3s server flags for the VM
+func serverArgs(vm *multipass.VM, opts InstallOptions) []string {
+	advertiseAddress := vm.Address()
+	if opts.AdvertiseAddress != "" {
+		advertiseAddress = opts.AdvertiseAddress
+	}
+
+	nodeIP := vm.Address()
+	if opts.NodeIP != "" {
+		nodeIP = opts.NodeIP
+	} else if IsDualStack(opts.ClusterCIDR) && net.ParseIP(vm.IPv4) != nil && net.ParseIP(vm.IPv6) != nil {
+		// Dual-stack clusters need a node IP from each family
+		nodeIP = vm.IPv4 + "," + vm.IPv6
+	}
+
+	// Traefik is disabled and the VM's IP is advertised unless overridden
+	args := []string{
+		"--disable=traefik",
+		"--advertise-address=" + advertiseAddress,
+		"--node-ip=" + nodeIP,
+	}
+
+	if opts.NodeName != "" {
+		args = append(args, "--node-name="+opts.NodeName)
+	}
+	if opts.TaintServer {
+		args = append(args, "--node-taint="+serverTaint)
+	}
+	if opts.ClusterCIDR != "" {
+		args = append(args, "--cluster-cidr="+opts.ClusterCIDR)
+	}
+	if opts.ServiceCIDR != "" {
+		args = append(args, "--service-cidr="+opts.ServiceCIDR)
+	}
+	if opts.ResolvConfPath != "" {
+		args = append(args, "--resolv-conf="+resolvConfPath)
+	}
+	if opts.ClusterInit {
+		args = append(args, "--cluster-init")
+	}
+	if opts.DataDir != "" {
+		args = append(args, "--data-dir="+opts.DataDir)
+	}
+
+	return args
+}
+
+// IsDualStack reports whether a comma-separated CIDR list covers both IPv4 and IPv6
+func IsDualStack(cidrs string) bool {
+	var hasIPv4, hasIPv6 bool
+	for _, cidr := range strings.Split(cidrs, ",") {
+		ip, _, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	return hasIPv4 && hasIPv6
+}
+
+// installK3sAirGapped transfers a local k3s binary, images and install script into the VM
+// and runs the installer without downloading anything, following the k3s airgap docs
+func installK3sAirGapped(mp *multipass.MultipassEnv, vmName string, installExec string, opts InstallOptions) error {
+	if opts.BinaryPath == "" || opts.InstallScriptPath == "" {
+		return fmt.Errorf("air-gapped install requires a k3s binary and install script")
+	}
+
+	// multipass transfer can only write where the default user has access, so stage in /tmp
+	if err := mp.CopyToVM(opts.BinaryPath, vmName, "/tmp/k3s"); err != nil {
+		return err
+	}
+	if err := mp.CopyToVM(opts.InstallScriptPath, vmName, "/tmp/k3s-install.sh"); err != nil {
+		return err
+	}
+
+	setupCmds := []string{
+		"sudo install -m 0755 /tmp/k3s /usr/local/bin/k3s",
+		"rm -f /tmp/k3s",
+	}
+
+	if opts.ImagesPath != "" {
+		imagesFile := filepath.Base(opts.ImagesPath)
+		if err := mp.CopyToVM(opts.ImagesPath, vmName, "/tmp/"+imagesFile); err != nil {
+			return err
+		}
+		imagesDir := dataDirOrDefault(opts.DataDir) + "/agent/images"
+		setupCmds = append(setupCmds,
+			"sudo mkdir -p "+imagesDir,
+			fmt.Sprintf("sudo mv /tmp/%s %s/", imagesFile, imagesDir),
+		)
+	}
+
+	output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", strings.Join(setupCmds, " && "))
+	if err != nil {
+		return fmt.Errorf("failed to place k3s artifacts: %w\n%s", err, output)
+	}
+
+	k3sInstallCmd := fmt.Sprintf(
+		"%s%sINSTALL_K3S_SKIP_DOWNLOAD=true INSTALL_K3S_EXEC=\"%s\" sh /tmp/k3s-install.sh",
+		proxyExports(opts), tokenEnv(opts), installExec,
+	)
+
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", k3sInstallCmd); err != nil {
+		return installError(err, output)
+	}
+	return nil
+}
+
+// Kubectl runs the k3s-bundled kubectl inside the VM and returns its output
+func Kubectl(mp *multipass.MultipassEnv, vmName string, args ...string) (string, error) {
+	execArgs := append([]string{"exec", vmName, "--", "sudo", "k3s", "kubectl"}, args...)
+	return mp.RunMultipassCmd(execArgs...)
+}
+
+// KubectlInteractive runs the k3s-bundled kubectl inside the VM attached to the terminal
+func KubectlInteractive(mp *multipass.MultipassEnv, vmName string, args ...string) error {
+	execArgs := append([]string{"exec", vmName, "--", "sudo", "k3s", "kubectl"}, args...)
+	return mp.RunMultipassCmdInteractive(execArgs...)
+}
+
+// pollInterval is how often readiness checks are retried
+const pollInterval = 2 * time.Second
+
+// WaitForReady waits until every node in the cluster reports Ready
+func WaitForReady(mp *multipass.MultipassEnv, vmName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		output, err := Kubectl(mp, vmName, "get", "nodes", "--no-headers")
+		if err == nil && nodesReady(output) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for nodes in %s to become Ready", timeout, vmName)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// nodesReady reports whether kubectl get nodes output lists at least one node and all are Ready
+func nodesReady(output string) bool {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return false
+	}
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] != "Ready" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WaitForAPIHealthy waits until the Kubernetes API server in the VM reports healthy.
+// A node can be Ready while the API server is still starting controllers, so this
+// polls the /healthz endpoint until it returns ok.
+func WaitForAPIHealthy(mp *multipass.MultipassEnv, vmName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		output, err := Kubectl(mp, vmName, "get", "--raw", "/healthz")
+		if err == nil && strings.TrimSpace(output) == "ok" {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for the API server in %s to become healthy", timeout, vmName)
+		}
+		time.Sleep(pollInterval)
+	}
 }
 
 // GetKubeconfig retrieves kubeconfig from a K3s node
 func GetKubeconfig(mp *multipass.MultipassEnv, vmName string) (string, error) {
-	output, err := mp.RunMultipassCmd("exec", vmName, "--", "sudo", "cat", "/etc/rancher/k3s/k3s.yaml")
+	output, err := readKubeconfig(mp, vmName)
 	if err != nil {
-		return "", fmt.Errorf("failed to get kubeconfig: %w", err)
+		return "", err
 	}
 
-	// Replace localhost with the VM's IP address
+	// Point the kubeconfig at the VM's IP address
 	vm, err := mp.GetVMByName(vmName)
 	if err != nil {
 		return "", err
 	}
 
-	kubeconfig := strings.ReplaceAll(output, "127.0.0.1", vm.IPv4)
-	kubeconfig = strings.ReplaceAll(kubeconfig, "localhost", vm.IPv4)
+	return rewriteKubeconfig(output, vm.Address(), vmName)
+}
+
+// rewriteKubeconfig points the server URLs of a k3s kubeconfig at host and renames
+// its "default" cluster, user and context to name. Only those fields are changed,
+// so certificate data and other values are left untouched.
+func rewriteKubeconfig(kubeconfig string, host string, name string) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	for _, cluster := range config.Clusters {
+		server, err := rewriteServerHost(cluster.Server, host)
+		if err != nil {
+			return "", err
+		}
+		cluster.Server = server
+	}
 
-	// Set the cluster and context names to match the VM name
-	kubeconfig = strings.ReplaceAll(kubeconfig, "default", vmName)
+	renameKey(config.Clusters, "default", name)
+	renameKey(config.AuthInfos, "default", name)
+	renameKey(config.Contexts, "default", name)
+	for _, context := range config.Contexts {
+		if context.Cluster == "default" {
+			context.Cluster = name
+		}
+		if context.AuthInfo == "default" {
+			context.AuthInfo = name
+		}
+	}
+	if config.CurrentContext == "default" {
+		config.CurrentContext = name
+	}
 
-	return kubeconfig, nil
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return string(data), nil
 }
 
-// SaveKubeconfig saves the kubeconfig to a file
-func SaveKubeconfig(kubeconfig string, outputPath string) error {
-	// Handle Windows path conversion if necessary
-	outputPath = normalizePath(outputPath)
+// rewriteServerHost replaces a loopback host in server with host, keeping the scheme and port
+func rewriteServerHost(server string, host string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse server URL %q: %w", server, err)
+	}
+
+	switch u.Hostname() {
+	case "127.0.0.1", "localhost", "::1":
+	default:
+		return server, nil
+	}
+
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else {
+		u.Host = host
+	}
+	return u.String(), nil
+}
+
+// PrefixKubeconfig prepends prefix to the names of every cluster, user and
+// context in a kubeconfig, and to the references between them
+func PrefixKubeconfig(kubeconfig string, prefix string) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	for _, name := range slices.Collect(maps.Keys(config.Clusters)) {
+		renameKey(config.Clusters, name, prefix+name)
+	}
+	for _, name := range slices.Collect(maps.Keys(config.AuthInfos)) {
+		renameKey(config.AuthInfos, name, prefix+name)
+	}
+	for _, name := range slices.Collect(maps.Keys(config.Contexts)) {
+		renameKey(config.Contexts, name, prefix+name)
+	}
+	for _, context := range config.Contexts {
+		context.Cluster = prefix + context.Cluster
+		context.AuthInfo = prefix + context.AuthInfo
+	}
+	if config.CurrentContext != "" {
+		config.CurrentContext = prefix + config.CurrentContext
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return string(data), nil
+}
+
+// renameKey moves the entry at from to to, if present
+func renameKey[T any](m map[string]T, from string, to string) {
+	if v, ok := m[from]; ok && from != to {
+		delete(m, from)
+		m[to] = v
+	}
+}
+
+// readKubeconfig reads the k3s kubeconfig from the VM. k3s writes the file during
+// its first start, so a missing file is retried for a short while before giving up.
+// sudo runs non-interactively so a password prompt fails instead of hanging.
+func readKubeconfig(mp *multipass.MultipassEnv, vmName string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		output, err := mp.ExecWithTimeout(vmName, kubeconfigReadTimeout, "sudo", "-n", "cat", kubeconfigPath)
+		if err == nil {
+			return output, nil
+		}
+
+		if isSudoPasswordPrompt(output) {
+			return "", fmt.Errorf("%w in %s: configure passwordless sudo for the default user, or copy %s out of the VM manually", ErrSudoPassword, vmName, kubeconfigPath)
+		}
+
+		if !strings.Contains(output, "No such file or directory") {
+			return "", fmt.Errorf("failed to get kubeconfig: %w\n%s", err, output)
+		}
+
+		if attempt >= kubeconfigAttempts {
+			return "", fmt.Errorf("%w: %s was not created in %s", ErrK3sNotInitialized, kubeconfigPath, vmName)
+		}
+		time.Sleep(kubeconfigRetryDelay)
+	}
+}
+
+// isSudoPasswordPrompt reports whether the output of sudo -n shows that a
+// password was required
+func isSudoPasswordPrompt(output string) bool {
+	return strings.Contains(output, "a password is required") ||
+		strings.Contains(output, "a terminal is required")
+}
+
+// SaveKubeconfig saves the kubeconfig to a file. Windows and WSL forms of
+// outputPath are converted to the one the local filesystem uses.
+func SaveKubeconfig(mp *multipass.MultipassEnv, kubeconfig string, outputPath string) error {
+	outputPath = mp.LocalPath(outputPath)
 
 	if filepath.Ext(outputPath) == "" {
 		outputPath = filepath.Join(outputPath, "config")
@@ -74,19 +613,125 @@ func SaveKubeconfig(kubeconfig string, outputPath string) error {
 	return nil
 }
 
-// MergeKubeconfigs combines multiple kubeconfigs into one
-func MergeKubeconfigs(kubeconfigs []string) (string, error) {
-	// TODO: Implement merging logic for multiple kubeconfigs
-	// For the MVP, we'll just return the first one
+// MergeOptions controls how kubeconfigs are merged
+type MergeOptions struct {
+	// SetCurrentContext sets the current context to the first cluster's context
+	SetCurrentContext bool
+	// Overwrite replaces entries that already exist under the same name instead of keeping the first
+	Overwrite bool
+}
+
+// MergeKubeconfigs combines multiple kubeconfigs into one. Inputs are merged in
+// order of cluster name so the result does not depend on the order they were
+// fetched in. The current context is left empty unless opts.SetCurrentContext is set.
+func MergeKubeconfigs(kubeconfigs []string, opts MergeOptions) (string, error) {
 	if len(kubeconfigs) == 0 {
 		return "", fmt.Errorf("no kubeconfigs provided")
 	}
 
-	return kubeconfigs[0], nil
+	configs := make([]*api.Config, 0, len(kubeconfigs))
+	for i, kubeconfig := range kubeconfigs {
+		config, err := clientcmd.Load([]byte(kubeconfig))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse kubeconfig %d: %w", i+1, err)
+		}
+		configs = append(configs, config)
+	}
+
+	sort.SliceStable(configs, func(i, j int) bool {
+		return firstClusterName(configs[i]) < firstClusterName(configs[j])
+	})
+
+	merged := api.NewConfig()
+	for _, config := range configs {
+		mergeConfig(merged, config, opts.Overwrite)
+	}
+
+	merged.CurrentContext = ""
+	if opts.SetCurrentContext {
+		merged.CurrentContext = configs[0].CurrentContext
+	}
+
+	output, err := clientcmd.Write(*merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize merged kubeconfig: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// firstClusterName returns the alphabetically first cluster name in a config
+func firstClusterName(config *api.Config) string {
+	first := ""
+	for name := range config.Clusters {
+		if first == "" || name < first {
+			first = name
+		}
+	}
+	return first
+}
+
+// mergeConfig copies the clusters, users and contexts of src into dst. On a name
+// collision the existing entry is kept unless overwrite is set, and the collision
+// is reported on stderr. The current context is not merged.
+func mergeConfig(dst *api.Config, src *api.Config, overwrite bool) {
+	for name, cluster := range src.Clusters {
+		if _, exists := dst.Clusters[name]; exists && !reportCollision("cluster", name, overwrite) {
+			continue
+		}
+		dst.Clusters[name] = cluster
+	}
+	for name, authInfo := range src.AuthInfos {
+		if _, exists := dst.AuthInfos[name]; exists && !reportCollision("user", name, overwrite) {
+			continue
+		}
+		dst.AuthInfos[name] = authInfo
+	}
+	for name, context := range src.Contexts {
+		if _, exists := dst.Contexts[name]; exists && !reportCollision("context", name, overwrite) {
+			continue
+		}
+		dst.Contexts[name] = context
+	}
+}
+
+// reportCollision logs a kubeconfig name collision and returns whether the entry should be replaced
+func reportCollision(kind string, name string, overwrite bool) bool {
+	if overwrite {
+		fmt.Fprintf(os.Stderr, "Warning: %s %q already exists, overwriting\n", kind, name)
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: %s %q already exists, skipping\n", kind, name)
+	}
+	return overwrite
 }
 
-// normalizePath handles path conversion between Windows and WSL paths
-func normalizePath(path string) string {
-	// If on Windows, ensure proper path separators
-	return filepath.FromSlash(path)
+// IsActive reports whether the k3s service is running in the VM
+func IsActive(mp *multipass.MultipassEnv, vmName string) bool {
+	output, err := mp.Exec(vmName, "systemctl", "is-active", "k3s")
+	return err == nil && strings.TrimSpace(output) == "active"
+}
+
+// TunnelKubeconfig points every server URL of a kubeconfig at 127.0.0.1:port,
+// where a tunnel started with mpkube tunnel forwards to the API server. The k3s
+// serving certificate is valid for 127.0.0.1, so TLS verification still works.
+func TunnelKubeconfig(kubeconfig string, port int) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	for _, cluster := range config.Clusters {
+		u, err := url.Parse(cluster.Server)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse server URL %q: %w", cluster.Server, err)
+		}
+		u.Host = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		cluster.Server = u.String()
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return string(data), nil
 }
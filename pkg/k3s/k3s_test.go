@@ -0,0 +1,146 @@
+package k3s
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// testKubeconfig returns a k3s-style kubeconfig whose cluster, user and context are all named name
+func testKubeconfig(name string, server string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: %[2]s
+    certificate-authority-data: Y2VydA==
+users:
+- name: %[1]s
+  user:
+    token: secret-%[1]s
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+current-context: %[1]s
+`, name, server)
+}
+
+func TestMergeKubeconfigsIsStable(t *testing.T) {
+	inputs := []string{
+		testKubeconfig("mpkube-b", "https://10.0.0.3:6443"),
+		testKubeconfig("mpkube-a", "https://10.0.0.2:6443"),
+		testKubeconfig("mpkube-c", "https://10.0.0.4:6443"),
+	}
+	reversed := []string{inputs[2], inputs[1], inputs[0]}
+
+	first, err := MergeKubeconfigs(inputs, MergeOptions{SetCurrentContext: true})
+	if err != nil {
+		t.Fatalf("first merge: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		again, err := MergeKubeconfigs(inputs, MergeOptions{SetCurrentContext: true})
+		if err != nil {
+			t.Fatalf("merge %d: %v", i+2, err)
+		}
+		if again != first {
+			t.Fatalf("merge %d differs from the first:\n%s\nwant:\n%s", i+2, again, first)
+		}
+	}
+
+	other, err := MergeKubeconfigs(reversed, MergeOptions{SetCurrentContext: true})
+	if err != nil {
+		t.Fatalf("reversed merge: %v", err)
+	}
+	if other != first {
+		t.Fatalf("merge depends on input order:\n%s\nwant:\n%s", other, first)
+	}
+}
+
+func TestRewriteKubeconfigOnlyChangesServer(t *testing.T) {
+	// The decoded certificate data contains loopback addresses, which a textual
+	// replacement of 127.0.0.1 would corrupt
+	certData := base64.StdEncoding.EncodeToString([]byte("-----BEGIN CERTIFICATE-----\nhttps://127.0.0.1:6443 127.0.0.1 localhost\n-----END CERTIFICATE-----\n"))
+	keyData := base64.StdEncoding.EncodeToString([]byte("127.0.0.1:6443\x00\x7f\x00\x00\x01"))
+
+	kubeconfig := func(server string) string {
+		return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: default
+  user:
+    client-certificate-data: %s
+    client-key-data: %s
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+current-context: default
+`, server, certData, certData, keyData)
+	}
+
+	got, err := rewriteKubeconfig(kubeconfig("https://127.0.0.1:6443"), "10.0.0.2", "default")
+	if err != nil {
+		t.Fatalf("rewriteKubeconfig: %v", err)
+	}
+
+	expected, err := clientcmd.Load([]byte(kubeconfig("https://10.0.0.2:6443")))
+	if err != nil {
+		t.Fatalf("failed to parse expected kubeconfig: %v", err)
+	}
+	want, err := clientcmd.Write(*expected)
+	if err != nil {
+		t.Fatalf("failed to serialize expected kubeconfig: %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("rewriteKubeconfig changed more than the server:\n%s\nwant:\n%s", got, want)
+	}
+
+	if !strings.Contains(got, "certificate-authority-data: "+certData) {
+		t.Errorf("certificate data was modified:\n%s", got)
+	}
+	if !strings.Contains(got, "client-key-data: "+keyData) {
+		t.Errorf("client key data was modified:\n%s", got)
+	}
+}
+
+func TestRewriteKubeconfigRenamesDefault(t *testing.T) {
+	got, err := rewriteKubeconfig(testKubeconfig("default", "https://127.0.0.1:6443"), "10.0.0.2", "mpkube-dev")
+	if err != nil {
+		t.Fatalf("rewriteKubeconfig: %v", err)
+	}
+
+	config, err := clientcmd.Load([]byte(got))
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	cluster, ok := config.Clusters["mpkube-dev"]
+	if !ok {
+		t.Fatalf("cluster was not renamed:\n%s", got)
+	}
+	if cluster.Server != "https://10.0.0.2:6443" {
+		t.Errorf("server = %q, want %q", cluster.Server, "https://10.0.0.2:6443")
+	}
+	if string(cluster.CertificateAuthorityData) != "cert" {
+		t.Errorf("certificate data = %q, want %q", cluster.CertificateAuthorityData, "cert")
+	}
+	context, ok := config.Contexts["mpkube-dev"]
+	if !ok || context.Cluster != "mpkube-dev" || context.AuthInfo != "mpkube-dev" {
+		t.Errorf("context was not renamed:\n%s", got)
+	}
+	if config.CurrentContext != "mpkube-dev" {
+		t.Errorf("current-context = %q, want %q", config.CurrentContext, "mpkube-dev")
+	}
+}
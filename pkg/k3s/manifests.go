@@ -0,0 +1,186 @@
+package k3s
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestsDir is the directory, relative to the k3s data directory, that k3s
+// watches and applies manifests from
+const manifestsDir = "server/manifests"
+
+// manifestExtensions are the file types picked up from manifest directories
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// ManifestFiles expands paths into the manifest files to deploy. Directories
+// contribute their top-level .yaml, .yml and .json files in name order.
+func ManifestFiles(paths []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]string)
+
+	add := func(file string) error {
+		// k3s keys manifests by file name, so two files with the same name would clash
+		base := filepath.Base(file)
+		if prev, ok := seen[base]; ok {
+			return fmt.Errorf("manifests %s and %s have the same file name", prev, file)
+		}
+		seen[base] = file
+		files = append(files, file)
+		return nil
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		if !info.IsDir() {
+			if err := add(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.Type().IsRegular() && slices.Contains(manifestExtensions, ext) {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no manifests (*.yaml, *.yml, *.json) found in %s", path)
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			if err := add(filepath.Join(path, name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// DeployManifests copies manifest files into the k3s manifests directory of the VM,
+// where k3s applies them automatically. dataDir is the k3s data directory, or "" for the default.
+func DeployManifests(mp *multipass.MultipassEnv, vmName string, dataDir string, files []string) error {
+	for _, file := range files {
+		base := filepath.Base(file)
+		tmpPath := "/tmp/mpkube-manifest-" + base
+
+		if err := mp.CopyToVM(file, vmName, tmpPath); err != nil {
+			return err
+		}
+
+		dst := dataDirOrDefault(dataDir) + "/" + manifestsDir + "/" + base
+		installCmd := fmt.Sprintf("sudo install -D -m 0600 -o root -g root %s %s && rm -f %s",
+			shellQuote(tmpPath), shellQuote(dst), shellQuote(tmpPath))
+		if _, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", installCmd); err != nil {
+			return fmt.Errorf("failed to deploy manifest %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// rolloutKinds are the workload kinds kubectl rollout status can wait for
+var rolloutKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// Workload is a Deployment, StatefulSet or DaemonSet declared in a manifest
+type Workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// String returns the workload as kubectl refers to it, e.g. deployment/web
+func (w Workload) String() string {
+	return strings.ToLower(w.Kind) + "/" + w.Name
+}
+
+// ManifestWorkloads returns the workloads declared in manifest files, in order.
+// Namespaced workloads without a namespace are placed in "default".
+func ManifestWorkloads(files []string) ([]Workload, error) {
+	var workloads []Workload
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		dec := yaml.NewDecoder(f)
+		for {
+			var doc struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Name      string `yaml:"name"`
+					Namespace string `yaml:"namespace"`
+				} `yaml:"metadata"`
+			}
+			err := dec.Decode(&doc)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("failed to parse manifest %s: %w", file, err)
+			}
+
+			if !slices.Contains(rolloutKinds, doc.Kind) || doc.Metadata.Name == "" {
+				continue
+			}
+			namespace := doc.Metadata.Namespace
+			if namespace == "" {
+				namespace = "default"
+			}
+			workloads = append(workloads, Workload{Kind: doc.Kind, Name: doc.Metadata.Name, Namespace: namespace})
+		}
+		f.Close()
+	}
+
+	return workloads, nil
+}
+
+// WaitForWorkloads waits until every workload has finished rolling out. k3s
+// applies manifests in the background, so a workload that does not exist yet
+// is retried until the timeout.
+func WaitForWorkloads(mp *multipass.MultipassEnv, vmName string, workloads []Workload, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for _, w := range workloads {
+		for {
+			remaining := time.Until(deadline).Round(time.Second)
+			if remaining < time.Second {
+				return fmt.Errorf("timed out after %s waiting for %s in namespace %s to roll out", timeout, w, w.Namespace)
+			}
+
+			_, err := Kubectl(mp, vmName, "rollout", "status", w.String(), "-n", w.Namespace,
+				fmt.Sprintf("--timeout=%s", remaining))
+			if err == nil {
+				break
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package k3s
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// nodeRoleLabelPrefix marks node roles in Kubernetes node labels
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// nodeRolePattern matches a role that is valid as the name part of a label key
+var nodeRolePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// nodeRegisterTimeout bounds how long LabelNodeRole waits for the node to register
+const nodeRegisterTimeout = 2 * time.Minute
+
+// ValidateNodeRole checks that role can be used in a node-role.kubernetes.io label
+func ValidateNodeRole(role string) error {
+	if len(role) > 63 || !nodeRolePattern.MatchString(role) {
+		return fmt.Errorf("invalid node role %q (must be at most 63 letters, digits, '-', '_' or '.', starting and ending with a letter or digit)", role)
+	}
+	return nil
+}
+
+// LabelNodeRole labels the node with node-role.kubernetes.io/<role>=true so
+// kubectl get nodes shows the role. The kubelet may not set node-role labels
+// itself, so k3s --node-label cannot be used and the label is applied through
+// the API once the node has registered.
+func LabelNodeRole(mp *multipass.MultipassEnv, vmName string, nodeName string, role string) error {
+	deadline := time.Now().Add(nodeRegisterTimeout)
+
+	for {
+		output, err := Kubectl(mp, vmName, "label", "node", nodeName, nodeRoleLabelPrefix+role+"=true", "--overwrite")
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to label node %s with role %s: %w\n%s", nodeName, role, err, output)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Node is the summary of a Kubernetes node shown by mpkube
+type Node struct {
+	Name             string   `json:"name"`
+	Status           string   `json:"status"`
+	Roles            []string `json:"roles"`
+	Version          string   `json:"version"`
+	InternalIP       string   `json:"internalIP"`
+	OSImage          string   `json:"osImage"`
+	ContainerRuntime string   `json:"containerRuntime"`
+}
+
+// nodeList mirrors the parts of kubectl get nodes -o json used by mpkube
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Unschedulable bool `json:"unschedulable"`
+		} `json:"spec"`
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+			Addresses []struct {
+				Type    string `json:"type"`
+				Address string `json:"address"`
+			} `json:"addresses"`
+			NodeInfo struct {
+				KubeletVersion          string `json:"kubeletVersion"`
+				OSImage                 string `json:"osImage"`
+				ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+			} `json:"nodeInfo"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// NodesJSON returns the output of kubectl get nodes -o json for the cluster
+func NodesJSON(mp *multipass.MultipassEnv, vmName string) (string, error) {
+	output, err := Kubectl(mp, vmName, "get", "nodes", "-o", "json")
+	if err != nil {
+		return "", fmt.Errorf("failed to get nodes: %w\n%s", err, output)
+	}
+	return output, nil
+}
+
+// ParseNodes parses the output of kubectl get nodes -o json
+func ParseNodes(output string) ([]Node, error) {
+	var list nodeList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse nodes: %w", err)
+	}
+
+	nodes := make([]Node, 0, len(list.Items))
+	for _, item := range list.Items {
+		node := Node{
+			Name:             item.Metadata.Name,
+			Status:           "NotReady",
+			Version:          item.Status.NodeInfo.KubeletVersion,
+			OSImage:          item.Status.NodeInfo.OSImage,
+			ContainerRuntime: item.Status.NodeInfo.ContainerRuntimeVersion,
+		}
+
+		for _, condition := range item.Status.Conditions {
+			if condition.Type == "Ready" && condition.Status == "True" {
+				node.Status = "Ready"
+			}
+		}
+		if item.Spec.Unschedulable {
+			node.Status += ",SchedulingDisabled"
+		}
+
+		for label := range item.Metadata.Labels {
+			if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+				node.Roles = append(node.Roles, role)
+			}
+		}
+		sort.Strings(node.Roles)
+
+		for _, address := range item.Status.Addresses {
+			if address.Type == "InternalIP" && node.InternalIP == "" {
+				node.InternalIP = address.Address
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,21 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// PortForward runs kubectl port-forward inside the VM, forwarding vmPort on the
+// VM's loopback interface to remotePort of resource (e.g. svc/web) until ctx is
+// cancelled. kubectl's output is written to w.
+func PortForward(ctx context.Context, mp *multipass.MultipassEnv, vmName string, namespace string, resource string, vmPort int, remotePort int, w io.Writer) error {
+	args := []string{"sudo", "k3s", "kubectl"}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "port-forward", resource, fmt.Sprintf("%d:%d", vmPort, remotePort))
+	return mp.ExecContext(ctx, vmName, w, w, args...)
+}
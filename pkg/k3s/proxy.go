@@ -0,0 +1,79 @@
+package k3s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// Environment files loaded by the k3s server and agent systemd units
+const (
+	serverEnvPath = "/etc/systemd/system/k3s.service.env"
+	agentEnvPath  = "/etc/systemd/system/k3s-agent.service.env"
+)
+
+// hasProxy reports whether any proxy setting was provided
+func hasProxy(opts InstallOptions) bool {
+	return opts.HTTPProxy != "" || opts.HTTPSProxy != "" || opts.NoProxy != ""
+}
+
+// proxyVars returns the proxy environment variables as NAME=value pairs.
+// Both cases are set because curl only honors the lowercase http_proxy.
+func proxyVars(opts InstallOptions) []string {
+	var vars []string
+
+	add := func(name string, value string) {
+		if value == "" {
+			return
+		}
+		vars = append(vars, strings.ToUpper(name)+"="+value, strings.ToLower(name)+"="+value)
+	}
+
+	add("HTTP_PROXY", opts.HTTPProxy)
+	add("HTTPS_PROXY", opts.HTTPSProxy)
+	add("NO_PROXY", opts.NoProxy)
+
+	return vars
+}
+
+// proxyExports returns a shell prefix exporting the proxy variables, or "" if none are set
+func proxyExports(opts InstallOptions) string {
+	vars := proxyVars(opts)
+	if len(vars) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(vars))
+	for i, v := range vars {
+		name, value, _ := strings.Cut(v, "=")
+		quoted[i] = name + "=" + shellQuote(value)
+	}
+
+	return "export " + strings.Join(quoted, " ") + "; "
+}
+
+// writeProxyEnv persists the proxy variables into envPath, the environment file
+// of the k3s server or agent unit, so containerd uses them for image pulls after install
+func writeProxyEnv(mp *multipass.MultipassEnv, vmName string, envPath string, opts InstallOptions) error {
+	var quoted []string
+	for _, v := range proxyVars(opts) {
+		quoted = append(quoted, shellQuote(v))
+	}
+
+	writeCmd := fmt.Sprintf(
+		"sudo mkdir -p /etc/systemd/system && printf '%%s\\n' %s | sudo tee %s > /dev/null && sudo chmod 0600 %s",
+		strings.Join(quoted, " "), envPath, envPath,
+	)
+
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", writeCmd); err != nil {
+		return fmt.Errorf("failed to write k3s proxy environment: %w\n%s", err, output)
+	}
+
+	return nil
+}
+
+// shellQuote quotes s for safe use as a single word in a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,100 @@
+package k3s
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+	"gopkg.in/yaml.v3"
+)
+
+// registriesPath is where k3s reads containerd registry configuration from
+const registriesPath = "/etc/rancher/k3s/registries.yaml"
+
+// RegistryAuth holds the credentials for a private registry
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+// registriesConfig mirrors the k3s registries.yaml format
+type registriesConfig struct {
+	Configs map[string]registryConfig `yaml:"configs,omitempty"`
+}
+
+// registryConfig is the per-host section of registries.yaml
+type registryConfig struct {
+	Auth *registryAuthConfig `yaml:"auth,omitempty"`
+	TLS  *registryTLSConfig  `yaml:"tls,omitempty"`
+}
+
+// registryAuthConfig is the auth section of a registry host config
+type registryAuthConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// registryTLSConfig is the tls section of a registry host config
+type registryTLSConfig struct {
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// hasRegistryConfig reports whether the options require a registries.yaml
+func hasRegistryConfig(opts InstallOptions) bool {
+	return len(opts.RegistryAuth) > 0 || len(opts.RegistryInsecure) > 0
+}
+
+// registriesYAML renders the registries.yaml content for the install options
+func registriesYAML(opts InstallOptions) ([]byte, error) {
+	config := registriesConfig{Configs: map[string]registryConfig{}}
+
+	for host, auth := range opts.RegistryAuth {
+		config.Configs[host] = registryConfig{
+			Auth: &registryAuthConfig{Username: auth.Username, Password: auth.Password},
+		}
+	}
+
+	for _, host := range opts.RegistryInsecure {
+		hostConfig := config.Configs[host]
+		hostConfig.TLS = &registryTLSConfig{InsecureSkipVerify: true}
+		config.Configs[host] = hostConfig
+	}
+
+	return yaml.Marshal(config)
+}
+
+// writeRegistriesConfig writes registries.yaml into the VM before k3s starts.
+// The file holds credentials, so it is only readable by root and its content
+// is never included in errors or output.
+func writeRegistriesConfig(mp *multipass.MultipassEnv, vmName string, opts InstallOptions) error {
+	data, err := registriesYAML(opts)
+	if err != nil {
+		return fmt.Errorf("failed to render registries.yaml: %w", err)
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	tmpFile, err := os.CreateTemp("", "mpkube-registries-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary registries.yaml: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temporary registries.yaml: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary registries.yaml: %w", err)
+	}
+
+	if err := mp.CopyToVM(tmpFile.Name(), vmName, "/tmp/registries.yaml"); err != nil {
+		return err
+	}
+
+	installCmd := fmt.Sprintf("sudo install -D -m 0600 -o root -g root /tmp/registries.yaml %s && rm -f /tmp/registries.yaml", registriesPath)
+	if _, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", installCmd); err != nil {
+		return fmt.Errorf("failed to install registries.yaml: %w", err)
+	}
+
+	return nil
+}
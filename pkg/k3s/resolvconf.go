@@ -0,0 +1,24 @@
+package k3s
+
+import (
+	"fmt"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// resolvConfPath is where a custom resolv.conf for k3s is placed in the VM
+const resolvConfPath = "/etc/rancher/k3s/resolv.conf"
+
+// writeResolvConf copies the custom resolv.conf into the VM for the kubelet and CoreDNS
+func writeResolvConf(mp *multipass.MultipassEnv, vmName string, opts InstallOptions) error {
+	if err := mp.CopyToVM(opts.ResolvConfPath, vmName, "/tmp/resolv.conf"); err != nil {
+		return err
+	}
+
+	installCmd := fmt.Sprintf("sudo install -D -m 0644 -o root -g root /tmp/resolv.conf %s && rm -f /tmp/resolv.conf", resolvConfPath)
+	if output, err := mp.RunMultipassCmd("exec", vmName, "--", "bash", "-c", installCmd); err != nil {
+		return fmt.Errorf("failed to install resolv.conf: %w\n%s", err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package k3s
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rodneyxr/mpkube/pkg/multipass"
+)
+
+// scriptDir is where scripts are copied to inside the VM before they run
+const scriptDir = "/tmp/mpkube-scripts"
+
+// RunScript copies a local script into the VM and runs it as root with
+// KUBECONFIG pointing at the cluster, so it can use kubectl or helm directly.
+// The script's output is streamed to stream when it is not nil, and the end of
+// the output is included in the error if the script fails.
+func RunScript(mp *multipass.MultipassEnv, vmName string, scriptPath string, stream io.Writer) error {
+	if _, err := os.Stat(scriptPath); err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+
+	name := filepath.Base(scriptPath)
+	vmPath := path.Join(scriptDir, name)
+	if _, err := mp.Exec(vmName, "mkdir", "-p", scriptDir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", scriptDir, err)
+	}
+	if err := mp.CopyToVM(scriptPath, vmName, vmPath); err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	w := io.Writer(&output)
+	if stream != nil {
+		w = io.MultiWriter(&output, stream)
+	}
+
+	runCmd := fmt.Sprintf("chmod +x %s && sudo KUBECONFIG=%s %s", shellQuote(vmPath), kubeconfigPath, shellQuote(vmPath))
+	if err := mp.ExecStream(vmName, w, "bash", "-c", runCmd); err != nil {
+		if tail := outputTail(output.String()); tail != "" {
+			return fmt.Errorf("script %s failed: %w\nScript output:\n%s", name, err, tail)
+		}
+		return fmt.Errorf("script %s failed: %w (no output)", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package k3s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ErrAPIUnreachable is returned when the API server in a kubeconfig cannot be
+// reached from the host
+var ErrAPIUnreachable = errors.New("API server not reachable from this host")
+
+// validateTimeout bounds the /version request made by ValidateKubeconfig
+const validateTimeout = 10 * time.Second
+
+// ValidateKubeconfig checks that kubeconfig is well formed and that its API
+// server answers /version with the kubeconfig's credentials. It returns the
+// server's git version.
+func ValidateKubeconfig(kubeconfig string) (string, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	restConfig.Timeout = validateTimeout
+
+	client, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, restConfig.Host+"/version", nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid kubeconfig server %q: %w", restConfig.Host, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s: %v. If mpkube runs in WSL with NAT networking, the VM's IP may only be reachable from Windows; try mirrored networking or run kubectl from Windows", ErrAPIUnreachable, restConfig.Host, err)
+		}
+		return "", fmt.Errorf("failed to reach %s: %w", restConfig.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API server %s answered /version with %s", restConfig.Host, resp.Status)
+	}
+
+	var version struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("failed to parse /version from %s: %w", restConfig.Host, err)
+	}
+
+	return version.GitVersion, nil
+}
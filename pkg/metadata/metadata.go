@@ -0,0 +1,123 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cluster records how a cluster was provisioned. Multipass does not keep this
+// information, so mpkube stores it in ~/.mpkube/clusters/<name>.json.
+type Cluster struct {
+	Name       string    `json:"name"`
+	CPUs       int       `json:"cpus"`
+	Memory     string    `json:"memory"`
+	Disk       string    `json:"disk"`
+	Image      string    `json:"image"`
+	K3sVersion string    `json:"k3sVersion,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Server is the cluster this VM joined as a worker; empty for servers
+	Server string `json:"server,omitempty"`
+	// ServerURL is the external k3s server this VM joined with create --server-url
+	ServerURL string `json:"serverURL,omitempty"`
+	// Workers are the VMs that joined this cluster as workers
+	Workers []string `json:"workers,omitempty"`
+	// Labels are arbitrary key/value pairs set with create --label
+	Labels map[string]string `json:"labels,omitempty"`
+	// DataDir is the k3s data directory set with create --data-dir; empty is the k3s default
+	DataDir string `json:"dataDir,omitempty"`
+}
+
+// IsAgent reports whether the VM joined another cluster as an agent, either an
+// mpkube cluster or an external server, instead of running a k3s server of its own
+func (c *Cluster) IsAgent() bool {
+	return c.Server != "" || c.ServerURL != ""
+}
+
+// MatchesLabels reports whether the cluster has every label in selector
+func (c *Cluster) MatchesLabels(selector map[string]string) bool {
+	for k, v := range selector {
+		if got, ok := c.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Dir returns the directory cluster metadata is stored in
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".mpkube", "clusters"), nil
+}
+
+// path returns the metadata file for a cluster
+func path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Save writes the metadata for a cluster
+func Save(c *Cluster) error {
+	p, err := path(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the metadata for a cluster. It returns an error wrapping
+// os.ErrNotExist if the cluster has no metadata, e.g. it predates metadata.
+func Load(name string) (*Cluster, error) {
+	p, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata for %s: %w", name, err)
+	}
+
+	var c Cluster
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata for %s: %w", name, err)
+	}
+
+	return &c, nil
+}
+
+// Delete removes the metadata for a cluster. Missing metadata is not an error.
+func Delete(name string) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete metadata for %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package multipass
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// envCacheTTL is how long a cached multipass environment is trusted
+const envCacheTTL = 24 * time.Hour
+
+// useEnvCache controls whether a cached environment is used, see SetEnvCache
+var useEnvCache = true
+
+// SetEnvCache enables or disables reading the environment cache. When disabled,
+// multipass is detected again and the cache is rewritten with the result.
+func SetEnvCache(enabled bool) {
+	useEnvCache = enabled
+}
+
+// envCache is the multipass environment resolved by a previous run. Detection
+// runs several wsl commands on Windows, so caching it keeps every command fast.
+type envCache struct {
+	Signature       string    `json:"signature"`
+	CreatedAt       time.Time `json:"createdAt"`
+	MultipassCmd    string    `json:"multipassCmd"`
+	UseWSLMultipass bool      `json:"useWSLMultipass"`
+	WSLDistro       string    `json:"wslDistro,omitempty"`
+}
+
+// envCachePath returns the location of the environment cache
+func envCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".mpkube", "env-cache.json"), nil
+}
+
+// envSignature identifies the inputs of multipass detection that are cheap to
+// check: the platform and the PATH used to look up multipass and wsl
+func envSignature(isWSL bool) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s|wsl=%t|%s", runtime.GOOS, runtime.GOARCH, isWSL, os.Getenv("PATH"))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadEnvCache returns the cached environment if it is fresh, matches the
+// signature and the cached command is still available
+func loadEnvCache(signature string) (*envCache, bool) {
+	if !useEnvCache {
+		return nil, false
+	}
+
+	path, err := envCachePath()
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache envCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		debugf("Ignoring unreadable environment cache %s: %v\n", path, err)
+		return nil, false
+	}
+
+	switch {
+	case cache.Signature != signature:
+		debugf("Environment changed since %s was written\n", path)
+		return nil, false
+	case time.Since(cache.CreatedAt) > envCacheTTL:
+		debugf("Environment cache %s expired\n", path)
+		return nil, false
+	case cache.MultipassCmd == "":
+		return nil, false
+	}
+
+	// Make sure multipass, or wsl to reach it, has not been uninstalled
+	lookup := cache.MultipassCmd
+	if cache.UseWSLMultipass {
+		lookup = "wsl"
+	}
+	if _, err := exec.LookPath(lookup); err != nil {
+		debugf("Cached multipass command %s is no longer available\n", lookup)
+		return nil, false
+	}
+
+	return &cache, true
+}
+
+// saveEnvCache records a resolved environment. Failures only cost the next run
+// a fresh detection, so they are not reported as errors.
+func saveEnvCache(m *MultipassEnv, signature string) {
+	path, err := envCachePath()
+	if err != nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(envCache{
+		Signature:       signature,
+		CreatedAt:       time.Now().UTC(),
+		MultipassCmd:    m.MultipassCmd,
+		UseWSLMultipass: m.UseWSLMultipass,
+		WSLDistro:       m.WSLDistro,
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		debugf("Failed to write environment cache: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		debugf("Failed to write environment cache: %v\n", err)
+	}
+}
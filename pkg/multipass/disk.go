@@ -0,0 +1,49 @@
+package multipass
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// diskSizePattern matches sizes reported by multipass get, e.g. 10GiB or 10.0GiB
+var diskSizePattern = regexp.MustCompile(`^(?i)([0-9]+(?:\.[0-9]+)?)\s*([KMGT]?)(?:i?B)?$`)
+
+// DiskSize returns the disk size allocated to a VM in bytes
+func (m *MultipassEnv) DiskSize(name string) (int64, error) {
+	output, err := m.RunMultipassCmd("get", "local."+name+".disk")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get disk size of %s: %v\nOutput: %s", name, err, output)
+	}
+	return parseDiskSize(strings.TrimSpace(output))
+}
+
+// SetDiskSize grows the disk of a VM to size, e.g. 20G. Multipass can only
+// resize the disk of a stopped VM and cannot shrink it.
+func (m *MultipassEnv) SetDiskSize(name string, size string) error {
+	output, err := m.RunMultipassCmd("set", "local."+name+".disk="+size)
+	if err != nil {
+		return fmt.Errorf("failed to set disk size of %s: %v\nOutput: %s", name, err, output)
+	}
+	return nil
+}
+
+// parseDiskSize converts a size reported by multipass into bytes
+func parseDiskSize(value string) (int64, error) {
+	match := diskSizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("unexpected disk size %q", value)
+	}
+
+	n, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected disk size %q: %w", value, err)
+	}
+
+	shift := strings.Index("KMGT", strings.ToUpper(match[2])) + 1
+	if match[2] == "" {
+		shift = 0
+	}
+	return int64(n * float64(int64(1)<<(10*shift))), nil
+}
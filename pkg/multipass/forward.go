@@ -0,0 +1,52 @@
+package multipass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// forwardScript relays stdin and stdout to a TCP port inside the VM using only
+// bash, so no extra packages are needed in the image
+const forwardScript = `exec 3<>/dev/tcp/127.0.0.1/%d && { cat <&3 & cat >&3; kill $! 2>/dev/null; }`
+
+// Forward accepts connections on listener and relays each one to port on the
+// VM's loopback interface through multipass exec. Because the traffic travels
+// over multipass itself, this works even when the VM's IP is not reachable
+// from the host, as is common with WSL2 NAT networking. Forward returns when
+// ctx is cancelled.
+func (m *MultipassEnv) Forward(ctx context.Context, listener net.Listener, vmName string, port int) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		go m.forwardConn(ctx, conn, vmName, port)
+	}
+}
+
+// forwardConn relays a single connection to port inside the VM
+func (m *MultipassEnv) forwardConn(ctx context.Context, conn net.Conn, vmName string, port int) {
+	defer conn.Close()
+
+	debugf("Forwarding %s to %s:%d\n", conn.RemoteAddr(), vmName, port)
+	cmd := m.command(ctx, "exec", vmName, "--", "bash", "-c", fmt.Sprintf(forwardScript, port))
+	cmd.Stdin = conn
+	cmd.Stdout = conn
+	// Don't keep waiting on the client once the process has exited
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		debugf("Forward to %s:%d ended: %v\n", vmName, port, err)
+	}
+}
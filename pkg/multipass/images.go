@@ -0,0 +1,160 @@
+package multipass
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Image represents an image that multipass can launch
+type Image struct {
+	Image   string   `json:"image"`
+	Remote  string   `json:"remote,omitempty"`
+	Aliases []string `json:"aliases"`
+	OS      string   `json:"os"`
+	Release string   `json:"release"`
+	Version string   `json:"version"`
+	Type    string   `json:"type"`
+}
+
+// Names returns every name the image can be launched by
+func (i Image) Names() []string {
+	names := []string{i.Image}
+	names = append(names, i.Aliases...)
+
+	if i.Remote != "" {
+		for _, name := range append([]string{i.Image}, i.Aliases...) {
+			names = append(names, i.Remote+":"+name)
+		}
+	}
+
+	return names
+}
+
+// ListImages returns the images available to launch
+func (m *MultipassEnv) ListImages() ([]Image, error) {
+	output, err := m.RunMultipassCmd("find", "--format", "csv")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w\n%s", err, output)
+	}
+
+	return parseMultipassFind(output)
+}
+
+// parseMultipassFind parses the CSV output of multipass find, mapping columns by header
+func parseMultipassFind(output string) ([]Image, error) {
+	records, err := csv.NewReader(strings.NewReader(strings.TrimSpace(output))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse multipass find output: %w", err)
+	}
+
+	if len(records) <= 1 {
+		return nil, nil // No images or just header
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var images []Image
+	for _, record := range records[1:] {
+		image := Image{
+			Image:   field(record, "image"),
+			Remote:  field(record, "remote"),
+			OS:      field(record, "os"),
+			Release: field(record, "release"),
+			Version: field(record, "version"),
+			Type:    field(record, "type"),
+		}
+		if image.Image == "" {
+			continue
+		}
+
+		// Aliases are separated by semicolons within the CSV field
+		for _, alias := range strings.Split(field(record, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				image.Aliases = append(image.Aliases, alias)
+			}
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// ValidateImage checks that name is a launchable image or alias, suggesting the
+// closest match when it is not. URLs and local image files are not checked.
+func (m *MultipassEnv) ValidateImage(name string) error {
+	if strings.Contains(name, "://") {
+		return nil
+	}
+
+	images, err := m.ListImages()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, image := range images {
+		for _, candidate := range image.Names() {
+			if candidate == name {
+				return nil
+			}
+			names = append(names, candidate)
+		}
+	}
+
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		return fmt.Errorf("image %q not found, did you mean %q? (run 'multipass find' to list images)", name, suggestion)
+	}
+	return fmt.Errorf("image %q not found (run 'multipass find' to list images)", name)
+}
+
+// closestMatch returns the candidate with the smallest edit distance to name
+func closestMatch(name string, candidates []string) string {
+	best := ""
+	bestDistance := -1
+
+	for _, candidate := range candidates {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if bestDistance == -1 || d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,129 @@
+package multipass
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VMInfo holds the detailed state and resources of a VM
+type VMInfo struct {
+	Name         string   `json:"name"`
+	State        string   `json:"state"`
+	IPv4         []string `json:"ipv4"`
+	Release      string   `json:"release"`
+	ImageRelease string   `json:"imageRelease"`
+	CPUs         int      `json:"cpus"`
+	MemoryTotal  int64    `json:"memoryTotal"`
+	MemoryUsed   int64    `json:"memoryUsed"`
+	DiskTotal    int64    `json:"diskTotal"`
+	DiskUsed     int64    `json:"diskUsed"`
+	Mounts       []Mount  `json:"mounts"`
+}
+
+// Mount is a host directory mounted into a VM
+type Mount struct {
+	SourcePath string `json:"sourcePath"`
+	TargetPath string `json:"targetPath"`
+}
+
+// flexInt decodes integers that multipass reports either as numbers or as strings.
+// Empty values, reported for stopped VMs, decode as zero.
+type flexInt int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s: %w", data, err)
+	}
+
+	*f = flexInt(n)
+	return nil
+}
+
+// multipassInfo mirrors the output of multipass info --format json
+type multipassInfo struct {
+	Errors []any                        `json:"errors"`
+	Info   map[string]multipassInstance `json:"info"`
+}
+
+// multipassInstance is the per-VM section of multipass info output
+type multipassInstance struct {
+	State        string   `json:"state"`
+	IPv4         []string `json:"ipv4"`
+	Release      string   `json:"release"`
+	ImageRelease string   `json:"image_release"`
+	CPUCount     flexInt  `json:"cpu_count"`
+	Memory       struct {
+		Total flexInt `json:"total"`
+		Used  flexInt `json:"used"`
+	} `json:"memory"`
+	Disks map[string]struct {
+		Total flexInt `json:"total"`
+		Used  flexInt `json:"used"`
+	} `json:"disks"`
+	Mounts map[string]struct {
+		SourcePath string `json:"source_path"`
+	} `json:"mounts"`
+}
+
+// GetVMInfo returns detailed information about a VM, including allocated resources and mounts
+func (m *MultipassEnv) GetVMInfo(name string) (*VMInfo, error) {
+	output, err := m.RunMultipassCmd("info", name, "--format", "json")
+	if err != nil {
+		if strings.Contains(output, "does not exist") {
+			return nil, fmt.Errorf("%w: %s", ErrVMNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to get info for %s: %w\n%s", name, err, output)
+	}
+
+	return parseMultipassInfo(name, output)
+}
+
+// parseMultipassInfo parses multipass info JSON output for a single VM
+func parseMultipassInfo(name string, output string) (*VMInfo, error) {
+	var parsed multipassInfo
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse multipass info output: %w", err)
+	}
+
+	instance, ok := parsed.Info[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrVMNotFound, name)
+	}
+
+	info := &VMInfo{
+		Name:         name,
+		State:        instance.State,
+		IPv4:         instance.IPv4,
+		Release:      instance.Release,
+		ImageRelease: instance.ImageRelease,
+		CPUs:         int(instance.CPUCount),
+		MemoryTotal:  int64(instance.Memory.Total),
+		MemoryUsed:   int64(instance.Memory.Used),
+		Mounts:       []Mount{},
+	}
+
+	for _, disk := range instance.Disks {
+		info.DiskTotal += int64(disk.Total)
+		info.DiskUsed += int64(disk.Used)
+	}
+
+	for target, mount := range instance.Mounts {
+		info.Mounts = append(info.Mounts, Mount{SourcePath: mount.SourcePath, TargetPath: target})
+	}
+	sort.Slice(info.Mounts, func(i, j int) bool {
+		return info.Mounts[i].TargetPath < info.Mounts[j].TargetPath
+	})
+
+	return info, nil
+}
@@ -2,17 +2,108 @@ package multipass
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
+var (
+	// ErrMultipassNotFound is returned when no usable multipass installation is found
+	ErrMultipassNotFound = errors.New("multipass not found")
+	// ErrVMNotFound is returned when a VM with the requested name does not exist
+	ErrVMNotFound = errors.New("VM not found")
+	// ErrTimeout is returned when a multipass command does not finish in time
+	ErrTimeout = errors.New("multipass command timed out")
+)
+
+// Default timeouts for multipass commands, see SetTimeouts
+const (
+	DefaultQueryTimeout = 1 * time.Minute
+	DefaultLongTimeout  = 30 * time.Minute
+)
+
+// queryCommands are the multipass subcommands that only read state and should finish quickly
+var queryCommands = []string{"list", "info", "version", "find", "networks", "get"}
+
+// queryTimeout and longTimeout bound multipass commands, see SetTimeouts
+var (
+	queryTimeout = DefaultQueryTimeout
+	longTimeout  = DefaultLongTimeout
+)
+
+// SetTimeouts sets how long multipass commands may run. The query timeout applies to
+// quick commands such as list and info; the long timeout applies to everything else,
+// such as launch, exec and transfer, which may download images or install software.
+// A zero duration disables the timeout.
+func SetTimeouts(query time.Duration, long time.Duration) {
+	queryTimeout = query
+	longTimeout = long
+}
+
+// timeoutFor returns the timeout for a multipass subcommand
+func timeoutFor(subcommand string) time.Duration {
+	if slices.Contains(queryCommands, subcommand) {
+		return queryTimeout
+	}
+	return longTimeout
+}
+
+// DefaultClusterPrefix is the VM name prefix that marks a VM as an mpkube cluster
+const DefaultClusterPrefix = "mpkube-"
+
+// clusterPrefix is the VM name prefix in use, see SetClusterPrefix
+var clusterPrefix = DefaultClusterPrefix
+
+// ClusterPrefix returns the VM name prefix that marks a VM as an mpkube cluster
+func ClusterPrefix() string {
+	return clusterPrefix
+}
+
+// SetClusterPrefix changes the VM name prefix, letting teams that share a
+// multipass host namespace their clusters. An empty prefix restores the default.
+func SetClusterPrefix(prefix string) {
+	if prefix == "" {
+		prefix = DefaultClusterPrefix
+	}
+	clusterPrefix = prefix
+}
+
+// verbose enables diagnostic output about multipass discovery, see SetVerbose
+var verbose bool
+
+// SetVerbose enables or disables diagnostic output on stderr
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// debugf prints a diagnostic message to stderr when verbose output is enabled
+func debugf(format string, a ...any) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, format, a...)
+	}
+}
+
+// ClusterVMName returns the VM name for a cluster, adding the cluster prefix if missing
+func ClusterVMName(name string) string {
+	if strings.HasPrefix(name, clusterPrefix) {
+		return name
+	}
+	return clusterPrefix + name
+}
+
 // MultipassEnv represents the Multipass environment
 type MultipassEnv struct {
 	IsWSL            bool
@@ -20,10 +111,17 @@ type MultipassEnv struct {
 	UseWSLMultipass  bool
 	MultipassCmd     string
 	WSLDistro        string
+	// RemoteHost is the SSH destination multipass runs on, or empty for local multipass
+	RemoteHost string
 }
 
-// NewMultipassEnv initializes a new MultipassEnv
+// NewMultipassEnv initializes a new MultipassEnv. The resolved multipass command
+// is cached in ~/.mpkube/env-cache.json so later runs can skip detection.
 func NewMultipassEnv() (*MultipassEnv, error) {
+	if remoteHost != "" {
+		return newRemoteEnv(remoteHost), nil
+	}
+
 	m := &MultipassEnv{
 		RunningOnWindows: runtime.GOOS == "windows",
 	}
@@ -31,6 +129,15 @@ func NewMultipassEnv() (*MultipassEnv, error) {
 	// Check if we're running in WSL
 	m.IsWSL = isWSL()
 
+	signature := envSignature(m.IsWSL)
+	if cache, ok := loadEnvCache(signature); ok {
+		debugf("Using cached multipass command %s\n", cache.MultipassCmd)
+		m.MultipassCmd = cache.MultipassCmd
+		m.UseWSLMultipass = cache.UseWSLMultipass
+		m.WSLDistro = cache.WSLDistro
+		return m, nil
+	}
+
 	// Determine multipass command
 	cmd, useWSLMultipass, wslDistro, err := getMultipassCmd(m.IsWSL, m.RunningOnWindows)
 	if err != nil {
@@ -40,9 +147,15 @@ func NewMultipassEnv() (*MultipassEnv, error) {
 	m.MultipassCmd = cmd
 	m.UseWSLMultipass = useWSLMultipass
 	m.WSLDistro = wslDistro
+	saveEnvCache(m, signature)
 	return m, nil
 }
 
+// InWSL reports whether mpkube is running in Windows Subsystem for Linux
+func InWSL() bool {
+	return isWSL()
+}
+
 // isWSL checks if we're running in Windows Subsystem for Linux
 func isWSL() bool {
 	// If we're on Windows, we're not in WSL
@@ -80,18 +193,22 @@ func getMultipassCmd(isWSL bool, isWindows bool) (string, bool, string, error) {
 		}
 
 		// If not found, check if we can access multipass through WSL
-		wslDistro, wslAvailable := checkWSLAvailable()
-		if wslAvailable {
-			// Try to verify multipass exists in the WSL environment
-			// Use --shell-type login to ensure the environment is properly loaded
-			cmd := exec.Command("wsl", "-d", wslDistro, "--shell-type", "login", "which", "multipass")
-			if err := cmd.Run(); err == nil {
-				// Multipass exists in WSL
-				return "multipass", true, wslDistro, nil
-			}
+		wslDistro, err := checkWSLAvailable()
+		if err != nil {
+			debugf("WSL: %v\n", err)
+			return "", false, "", fmt.Errorf("%w: searched %s and WSL (%v)", ErrMultipassNotFound, strings.Join(winPaths, ", "), err)
+		}
+
+		// Try to verify multipass exists in the WSL environment
+		// Use --shell-type login to ensure the environment is properly loaded
+		cmd := exec.Command("wsl", "-d", wslDistro, "--shell-type", "login", "which", "multipass")
+		if err := cmd.Run(); err == nil {
+			// Multipass exists in WSL
+			return "multipass", true, wslDistro, nil
 		}
+		debugf("WSL: multipass not found in distribution '%s'\n", wslDistro)
 
-		return "", false, "", fmt.Errorf("multipass not found in Windows or WSL")
+		return "", false, "", fmt.Errorf("%w: searched %s and WSL distribution '%s'", ErrMultipassNotFound, strings.Join(winPaths, ", "), wslDistro)
 	}
 
 	// Running in WSL
@@ -112,7 +229,7 @@ func getMultipassCmd(isWSL bool, isWindows bool) (string, bool, string, error) {
 			return "multipass", false, "", nil
 		}
 
-		return "", false, "", fmt.Errorf("multipass not found in WSL or Windows path")
+		return "", false, "", fmt.Errorf("%w: searched %s and PATH", ErrMultipassNotFound, strings.Join(paths, ", "))
 	}
 
 	// Not in WSL, just check if multipass is available
@@ -120,86 +237,439 @@ func getMultipassCmd(isWSL bool, isWindows bool) (string, bool, string, error) {
 		return "multipass", false, "", nil
 	}
 
-	return "", false, "", fmt.Errorf("multipass command not found")
+	return "", false, "", fmt.Errorf("%w: multipass command not in PATH", ErrMultipassNotFound)
 }
 
-// checkWSLAvailable checks if WSL is available and returns the default distribution
-func checkWSLAvailable() (string, bool) {
+// WSL distribution probe attempts; a distribution that is still booting can fail the first probe
+const (
+	wslProbeAttempts = 3
+	wslProbeDelay    = time.Second
+)
+
+// wslDistribution is a row of 'wsl -l -v'
+type wslDistribution struct {
+	Name    string
+	State   string
+	Default bool
+}
+
+// checkWSLAvailable checks if WSL is available and returns the distribution to
+// use: the default one if it is usable, then running ones, then any other.
+// The error describes why WSL cannot be used.
+func checkWSLAvailable() (string, error) {
 	// Check if WSL command exists
 	if _, err := exec.LookPath("wsl"); err != nil {
-		return "", false
+		return "", fmt.Errorf("wsl is not on PATH")
 	}
 
-	// Get default WSL distribution list (raw output)
-	cmd := exec.Command("wsl", "-l", "-q")
-	outputBytes, err := cmd.Output()
+	distros, err := listWSLDistributions()
 	if err != nil {
-		// If listing fails, WSL might still be available but without distributions or with an older version
-		// We can try a simpler check or assume it's not usable for our purpose here.
-		// For now, let's return false if listing fails.
-		return "", false
+		return "", err
 	}
 
-	// Decode UTF-16LE output from wsl -l -q
-	utf16Decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
-	reader := transform.NewReader(bytes.NewReader(outputBytes), utf16Decoder)
-	decodedBytes, err := io.ReadAll(reader)
-	if err != nil {
-		// Fallback or error handling if decoding fails
-		// Maybe try assuming UTF-8 as a last resort? Or just fail.
-		fmt.Fprintf(os.Stderr, "Warning: Failed to decode WSL distribution list as UTF-16: %v. Trying as UTF-8.\n", err)
-		decodedBytes = outputBytes // Use original bytes if decoding fails
+	// Try the default distribution first, where multipass is usually installed,
+	// then running ones, which answer the probe without booting
+	rank := func(d wslDistribution) int {
+		switch {
+		case d.Default:
+			return 0
+		case strings.EqualFold(d.State, "Running"):
+			return 1
+		default:
+			return 2
+		}
 	}
+	slices.SortStableFunc(distros, func(a, b wslDistribution) int {
+		return rank(a) - rank(b)
+	})
 
-	// Parse the distribution list
-	outputStr := string(decodedBytes)
-	distros := strings.Split(outputStr, "\n")
-	if len(distros) == 0 {
-		return "", false
+	// Find the first usable distribution
+	var tried []string
+	for _, distro := range distros {
+		if probeWSLDistro(distro.Name) {
+			return distro.Name, nil
+		}
+
+		// If the check fails, continue to the next one
+		debugf("Warning: WSL distribution '%s' found but seems unavailable. Trying next.\n", distro.Name)
+		tried = append(tried, distro.Name)
 	}
 
-	// Find the first valid distribution name
-	for _, distro := range distros {
-		// Clean up the name: remove carriage returns and trim whitespace
-		cleanedDistro := strings.TrimSpace(strings.ReplaceAll(distro, "\r", ""))
-		if cleanedDistro != "" {
-			// Check if this distribution is actually running or usable
-			// A simple check could be trying to run 'true' inside it
-			checkCmd := exec.Command("wsl", "-d", cleanedDistro, "true")
-			if checkCmd.Run() == nil {
-				return cleanedDistro, true
-			}
-			// If the check fails, continue to the next potential default
-			fmt.Fprintf(os.Stderr, "Warning: WSL distribution '%s' found but seems unavailable or stopped. Trying next.\n", cleanedDistro)
+	if len(tried) == 0 {
+		return "", fmt.Errorf("no WSL distributions installed")
+	}
+	return "", fmt.Errorf("no usable WSL distribution (tried %s)", strings.Join(tried, ", "))
+}
+
+// listWSLDistributions lists the installed WSL distributions. Older WSL
+// versions do not support 'wsl -l -v', so 'wsl -l -q' is used when it fails;
+// its names come without a state or default marker.
+func listWSLDistributions() ([]wslDistribution, error) {
+	// List distributions with their state; the default one is marked with *
+	outputBytes, err := exec.Command("wsl", "-l", "-v").Output()
+	if err == nil {
+		return parseWSLDistributions(decodeWSLOutput(outputBytes)), nil
+	}
+	debugf("WSL: 'wsl -l -v' failed (%v), falling back to 'wsl -l -q'\n", err)
+
+	outputBytes, quietErr := exec.Command("wsl", "-l", "-q").Output()
+	if quietErr != nil {
+		// If listing fails, WSL might still be available but without distributions
+		return nil, fmt.Errorf("failed to list WSL distributions: %w", errors.Join(err, quietErr))
+	}
+	return parseWSLNames(decodeWSLOutput(outputBytes)), nil
+}
+
+// parseWSLNames parses the decoded output of 'wsl -l -q', one name per line
+func parseWSLNames(output string) []wslDistribution {
+	var distros []wslDistribution
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(strings.ReplaceAll(line, "\r", ""))
+		if name != "" {
+			distros = append(distros, wslDistribution{Name: name})
 		}
 	}
+	return distros
+}
 
-	// If no valid/running distribution was found after checking all lines
-	return "", false
+// parseWSLDistributions parses the decoded output of 'wsl -l -v', which has a
+// header row followed by "[*] NAME STATE VERSION" rows
+func parseWSLDistributions(output string) []wslDistribution {
+	var distros []wslDistribution
+	header := true
+	for _, line := range strings.Split(output, "\n") {
+		// Clean up the line: remove carriage returns and trim whitespace
+		line = strings.TrimSpace(strings.ReplaceAll(line, "\r", ""))
+		if line == "" {
+			continue
+		}
+		// The header is localized, so it is skipped by position rather than content
+		if header {
+			header = false
+			continue
+		}
+
+		rest, isDefault := strings.CutPrefix(line, "*")
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		distro := wslDistribution{Name: fields[0], Default: isDefault}
+		if len(fields) > 1 {
+			distro.State = fields[1]
+		}
+		distros = append(distros, distro)
+	}
+	return distros
 }
 
-// RunMultipassCmd executes a multipass command and returns the output
-func (m *MultipassEnv) RunMultipassCmd(args ...string) (string, error) {
-	var cmd *exec.Cmd
+// probeWSLDistro reports whether a command can run in the distribution,
+// retrying briefly since a distribution that is booting can fail transiently
+func probeWSLDistro(distro string) bool {
+	for attempt := 1; attempt <= wslProbeAttempts; attempt++ {
+		if exec.Command("wsl", "-d", distro, "true").Run() == nil {
+			return true
+		}
+		if attempt < wslProbeAttempts {
+			debugf("WSL: probe %d of distribution '%s' failed, retrying\n", attempt, distro)
+			time.Sleep(wslProbeDelay)
+		}
+	}
+	return false
+}
+
+// decodeWSLOutput decodes the UTF-16LE output of wsl.exe, falling back to the raw bytes
+func decodeWSLOutput(outputBytes []byte) string {
+	utf16Decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
+	reader := transform.NewReader(bytes.NewReader(outputBytes), utf16Decoder)
+	decodedBytes, err := io.ReadAll(reader)
+	if err != nil {
+		debugf("Warning: Failed to decode WSL output as UTF-16: %v. Trying as UTF-8.\n", err)
+		return string(outputBytes) // Use original bytes if decoding fails
+	}
+	return string(decodedBytes)
+}
+
+// command builds the exec.Cmd for a multipass invocation in the current environment
+func (m *MultipassEnv) command(ctx context.Context, args ...string) *exec.Cmd {
+	// Multipass on another machine over SSH
+	if m.RemoteHost != "" {
+		return m.remoteCommand(ctx, false, args...)
+	}
 
 	// Windows using WSL multipass
 	if m.RunningOnWindows && m.UseWSLMultipass {
 		// Use --shell-type login to ensure the environment is properly loaded
+		// The login shell re-parses the command line, so each arg is quoted to survive it
 		wslArgs := []string{"-d", m.WSLDistro, "--shell-type", "login", "multipass"}
-		wslArgs = append(wslArgs, args...)
-		cmd = exec.Command("wsl", wslArgs...)
-	} else if m.IsWSL && strings.HasSuffix(m.MultipassCmd, ".exe") {
+		wslArgs = append(wslArgs, quoteShellArgs(args)...)
+		return exec.CommandContext(ctx, "wsl", wslArgs...)
+	}
+
+	if m.IsWSL && strings.HasSuffix(m.MultipassCmd, ".exe") {
 		// WSL using Windows multipass.exe
 		wslArgs := []string{"/c", m.MultipassCmd}
 		wslArgs = append(wslArgs, args...)
-		cmd = exec.Command("cmd.exe", wslArgs...)
-	} else {
-		// Native multipass in current environment
-		cmd = exec.Command(m.MultipassCmd, args...)
+		return exec.CommandContext(ctx, "cmd.exe", wslArgs...)
+	}
+
+	// Native multipass in current environment
+	return exec.CommandContext(ctx, m.MultipassCmd, args...)
+}
+
+// shellSafeChars are the characters that never need quoting in a POSIX shell word
+const shellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./-_"
+
+// quoteShellArgs quotes each argument so a POSIX shell parses it back as a single word
+func quoteShellArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteShellArg(arg)
 	}
+	return quoted
+}
+
+// quoteShellArg single-quotes arg if it contains characters the shell would interpret
+func quoteShellArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+
+	if strings.Trim(arg, shellSafeChars) == "" {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// RunMultipassCmd executes a multipass command and returns the output
+func (m *MultipassEnv) RunMultipassCmd(args ...string) (string, error) {
+	var output bytes.Buffer
+	err := m.run(args, &output, &output)
+	return output.String(), err
+}
+
+// run runs a multipass command with the timeout for its subcommand, writing its
+// stdout and stderr to the given writers
+func (m *MultipassEnv) run(args []string, stdout io.Writer, stderr io.Writer) error {
+	timeout := time.Duration(0)
+	if len(args) > 0 {
+		timeout = timeoutFor(args[0])
+	}
+	return m.runWithTimeout(args, timeout, stdout, stderr)
+}
+
+// runWithTimeout runs a multipass command that is killed after timeout. A zero
+// timeout disables the limit.
+func (m *MultipassEnv) runWithTimeout(args []string, timeout time.Duration, stdout io.Writer, stderr io.Writer) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := m.command(ctx, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait forever for output pipes held open by children of a killed command
+	cmd.WaitDelay = 5 * time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: multipass %s did not finish within %s", ErrTimeout, args[0], timeout)
+	}
+	return err
+}
+
+// RunMultipassCmdInteractive executes a multipass command attached to the
+// current terminal, streaming its output until it exits
+func (m *MultipassEnv) RunMultipassCmdInteractive(args ...string) error {
+	// Interactive commands such as port-forward run until the user stops them
+	cmd := m.command(context.Background(), args...)
+	if m.RemoteHost != "" {
+		cmd = m.remoteCommand(context.Background(), true, args...)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
 
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+// Exec runs a command inside a VM and returns its combined output
+func (m *MultipassEnv) Exec(vmName string, command ...string) (string, error) {
+	args := append([]string{"exec", vmName, "--"}, command...)
+	return m.RunMultipassCmd(args...)
+}
+
+// ExecWithTimeout executes a command inside a VM like Exec, but gives up after
+// timeout instead of the global operation timeout
+func (m *MultipassEnv) ExecWithTimeout(vmName string, timeout time.Duration, command ...string) (string, error) {
+	var output bytes.Buffer
+	args := append([]string{"exec", vmName, "--"}, command...)
+	err := m.runWithTimeout(args, timeout, &output, &output)
+	return output.String(), err
+}
+
+// ExecStream executes a command inside a VM, writing its combined output to w as it runs
+func (m *MultipassEnv) ExecStream(vmName string, w io.Writer, command ...string) error {
+	args := append([]string{"exec", vmName, "--"}, command...)
+	return m.run(args, w, w)
+}
+
+// ExecContext executes a command inside a VM until it exits or ctx is cancelled,
+// for long-running commands that have no timeout of their own
+func (m *MultipassEnv) ExecContext(ctx context.Context, vmName string, stdout io.Writer, stderr io.Writer, command ...string) error {
+	args := append([]string{"exec", vmName, "--"}, command...)
+	cmd := m.command(ctx, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = 5 * time.Second
+	return cmd.Run()
+}
+
+// ExecSplit executes a command inside a VM and returns its stdout and stderr separately
+func (m *MultipassEnv) ExecSplit(vmName string, command ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	args := append([]string{"exec", vmName, "--"}, command...)
+	err := m.run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+// Version returns the output of multipass version
+func (m *MultipassEnv) Version() (string, error) {
+	output, err := m.RunMultipassCmd("version")
+	if err != nil {
+		return "", fmt.Errorf("multipass version failed: %w\n%s", err, output)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// TransferFile copies a file between the host and a VM using multipass transfer.
+// Paths inside a VM use the <vm-name>:<path> form and host paths must already be
+// in the form the multipass binary expects (see CopyToVM and CopyFromVM).
+func (m *MultipassEnv) TransferFile(src string, dst string) error {
+	output, err := m.RunMultipassCmd("transfer", src, dst)
+	if err != nil {
+		return fmt.Errorf("failed to transfer %s to %s: %v\nOutput: %s", src, dst, err, output)
+	}
+	return nil
+}
+
+// CopyToVM copies a local file into a VM
+func (m *MultipassEnv) CopyToVM(localPath string, vmName string, vmPath string) error {
+	if m.RemoteHost != "" {
+		return m.copyToRemoteVM(localPath, vmName, vmPath)
+	}
+
+	hostPath, err := m.HostPath(localPath)
+	if err != nil {
+		return err
+	}
+	return m.TransferFile(hostPath, vmName+":"+vmPath)
+}
+
+// CopyFromVM copies a file from a VM to the local filesystem
+func (m *MultipassEnv) CopyFromVM(vmName string, vmPath string, localPath string) error {
+	if m.RemoteHost != "" {
+		return m.copyFromRemoteVM(vmName, vmPath, localPath)
+	}
+
+	hostPath, err := m.HostPath(localPath)
+	if err != nil {
+		return err
+	}
+	return m.TransferFile(vmName+":"+vmPath, hostPath)
+}
+
+// HostPath converts a local path into the form understood by the multipass binary.
+// Windows multipass.exe invoked from WSL needs Windows paths, and multipass running
+// inside WSL invoked from Windows needs /mnt/<drive> paths.
+func (m *MultipassEnv) HostPath(path string) (string, error) {
+	absPath, err := filepath.Abs(m.LocalPath(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	// WSL using Windows multipass.exe
+	if m.IsWSL && strings.HasSuffix(m.MultipassCmd, ".exe") {
+		if winPath, ok := wslToWindowsPath(absPath); ok {
+			return winPath, nil
+		}
+
+		// Paths inside the WSL filesystem need wslpath to get the \\wsl$ form
+		output, err := exec.Command("wslpath", "-w", absPath).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to convert %s to a Windows path: %w", absPath, err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	// Windows using WSL multipass
+	if m.RunningOnWindows && m.UseWSLMultipass {
+		if wslPath, ok := windowsToWSLPath(absPath); ok {
+			return wslPath, nil
+		}
+		return "", fmt.Errorf("cannot convert %s to a WSL path", absPath)
+	}
+
+	return absPath, nil
+}
+
+// LocalPath converts a path given by the user into the form the local
+// filesystem uses. In WSL, Windows drive paths such as C:\Users\me become
+// /mnt/c/Users/me; on Windows, /mnt/c/... paths become C:\.... Other paths
+// only get the platform's separators.
+func (m *MultipassEnv) LocalPath(path string) string {
+	switch {
+	case m.IsWSL:
+		if wslPath, ok := windowsToWSLPath(path); ok {
+			return wslPath
+		}
+	case m.RunningOnWindows:
+		if winPath, ok := wslToWindowsPath(path); ok {
+			return winPath
+		}
+	}
+	return filepath.FromSlash(path)
+}
+
+// wslToWindowsPath converts a /mnt/<drive>/... path to <DRIVE>:\...
+func wslToWindowsPath(path string) (string, bool) {
+	if !strings.HasPrefix(path, "/mnt/") || len(path) < 6 {
+		return "", false
+	}
+
+	drive := path[5]
+	rest := path[6:]
+	if !isDriveLetter(drive) || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+
+	if rest == "" {
+		rest = "/"
+	}
+
+	return strings.ToUpper(string(drive)) + ":" + strings.ReplaceAll(rest, "/", "\\"), true
+}
+
+// windowsToWSLPath converts a <DRIVE>:\... path to /mnt/<drive>/...
+func windowsToWSLPath(path string) (string, bool) {
+	if len(path) < 2 || path[1] != ':' || !isDriveLetter(path[0]) {
+		return "", false
+	}
+
+	// Drive-relative paths such as C:foo have no /mnt equivalent
+	rest := strings.ReplaceAll(path[2:], "\\", "/")
+	if rest != "" && rest[0] != '/' {
+		return "", false
+	}
+	return "/mnt/" + strings.ToLower(string(path[0])) + rest, true
+}
+
+// isDriveLetter reports whether c is an ASCII letter usable as a Windows drive
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
 }
 
 // ListVMs returns a list of multipass VMs
@@ -214,38 +684,85 @@ func (m *MultipassEnv) ListVMs() ([]VM, error) {
 
 // VM represents a multipass virtual machine
 type VM struct {
-	Name  string
-	State string
-	IPv4  string
-	Image string
-	IsK3s bool
+	Name  string `json:"name"`
+	State string `json:"state"`
+	IPv4  string `json:"ipv4"`
+	IPv6  string `json:"ipv6,omitempty"`
+	Image string `json:"image"`
+	IsK3s bool   `json:"managed"`
 }
 
-// parseMultipassList parses the output of multipass list command
+// Address returns the IP address used to reach the VM, preferring IPv4 and
+// falling back to IPv6. Multipass placeholders such as "--" yield "".
+func (vm VM) Address() string {
+	if net.ParseIP(vm.IPv4) != nil {
+		return vm.IPv4
+	}
+	if net.ParseIP(vm.IPv6) != nil {
+		return vm.IPv6
+	}
+	return ""
+}
+
+// listColumns is the column order of multipass list --format csv, used when the
+// output has no header line
+var listColumns = []string{"Name", "State", "IPv4", "IPv6", "Release", "AllIPv4"}
+
+// parseMultipassList parses the output of multipass list command. Columns are
+// located by the header, so reordered or missing columns do not break parsing.
 func parseMultipassList(output string) ([]VM, error) {
 	var vms []VM
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	if len(lines) <= 1 {
-		return vms, nil // No VMs or just header
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(output)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse multipass list output: %w", err)
+	}
+
+	if len(records) == 0 {
+		return vms, nil // No VMs
 	}
 
-	// Skip the header line
-	for _, line := range lines[1:] {
-		parts := strings.Split(line, ",")
-		if len(parts) < 4 {
-			continue
+	// Only skip the first line if it really is a header; otherwise assume the
+	// default multipass column order so the first VM is not dropped
+	header := listColumns
+	if isListHeader(records[0]) {
+		header = records[0]
+		records = records[1:]
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	for _, record := range records {
+		if len(record) < len(header) {
+			fmt.Fprintf(os.Stderr, "Warning: multipass list returned %d columns instead of %d for %q; missing fields are left empty.\n", len(record), len(header), strings.Join(record, ","))
 		}
 
 		vm := VM{
-			Name:  strings.TrimSpace(parts[0]),
-			State: strings.TrimSpace(parts[1]),
-			IPv4:  strings.TrimSpace(parts[2]),
-			Image: strings.TrimSpace(parts[4]),
+			Name:  field(record, "name"),
+			State: field(record, "state"),
+			IPv4:  field(record, "ipv4"),
+			IPv6:  field(record, "ipv6"),
+			Image: field(record, "release"),
+		}
+		if vm.Name == "" {
+			continue
 		}
 
 		// Check if this is a K3s VM by looking for cluster prefix
-		if strings.HasPrefix(vm.Name, "mpkube-") {
+		if strings.HasPrefix(vm.Name, clusterPrefix) {
 			vm.IsK3s = true
 		}
 
@@ -255,6 +772,21 @@ func parseMultipassList(output string) ([]VM, error) {
 	return vms, nil
 }
 
+// isListHeader reports whether record is the header line of multipass list,
+// which names both the Name and State columns in whatever order they come
+func isListHeader(record []string) bool {
+	var name, state bool
+	for _, field := range record {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "name":
+			name = true
+		case "state":
+			state = true
+		}
+	}
+	return name && state
+}
+
 // GetVMByName returns a VM by name
 func (m *MultipassEnv) GetVMByName(name string) (*VM, error) {
 	vms, err := m.ListVMs()
@@ -268,7 +800,7 @@ func (m *MultipassEnv) GetVMByName(name string) (*VM, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("VM %s not found", name)
+	return nil, fmt.Errorf("%w: %s", ErrVMNotFound, name)
 }
 
 // GetK3sVMs returns all K3s VMs
@@ -280,7 +812,7 @@ func (m *MultipassEnv) GetK3sVMs() ([]VM, error) {
 
 	var k3sVMs []VM
 	for _, vm := range vms {
-		if strings.HasPrefix(vm.Name, "mpkube-") {
+		if strings.HasPrefix(vm.Name, clusterPrefix) {
 			k3sVMs = append(k3sVMs, vm)
 		}
 	}
@@ -302,6 +834,64 @@ func (m *MultipassEnv) DeleteVM(name string) error {
 		}
 		return fmt.Errorf("failed to delete VM %s: %v\nOutput: %s", name, err, output)
 	}
-	fmt.Printf("VM %s deleted successfully.\n", name)
+
+	// Make sure the VM is really gone before reporting success
+	if _, err := m.GetVMByName(name); !errors.Is(err, ErrVMNotFound) {
+		if err != nil {
+			return fmt.Errorf("failed to verify deletion of VM %s: %w", name, err)
+		}
+		return fmt.Errorf("VM %s still exists after delete", name)
+	}
+
+	return nil
+}
+
+// Purge permanently removes all deleted VMs and reclaims their disk space
+func (m *MultipassEnv) Purge() error {
+	output, err := m.RunMultipassCmd("purge")
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted VMs: %v\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+// StopVM stops a running multipass VM
+func (m *MultipassEnv) StopVM(name string) error {
+	output, err := m.RunMultipassCmd("stop", name)
+	if err != nil {
+		return fmt.Errorf("failed to stop VM %s: %v\nOutput: %s", name, err, output)
+	}
 	return nil
 }
+
+// StartVM starts a stopped multipass VM
+func (m *MultipassEnv) StartVM(name string) error {
+	output, err := m.RunMultipassCmd("start", name)
+	if err != nil {
+		return fmt.Errorf("failed to start VM %s: %v\nOutput: %s", name, err, output)
+	}
+	return nil
+}
+
+// statePollInterval is how often WaitForState checks the VM state
+const statePollInterval = 2 * time.Second
+
+// WaitForState waits until the VM reports the given state, e.g. "Running" or "Stopped"
+func (m *MultipassEnv) WaitForState(name string, state string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		vm, err := m.GetVMByName(name)
+		if errors.Is(err, ErrVMNotFound) {
+			return err
+		}
+		if err == nil && strings.EqualFold(vm.State, state) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for VM %s to be %s", timeout, name, state)
+		}
+		time.Sleep(statePollInterval)
+	}
+}
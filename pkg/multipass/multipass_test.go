@@ -0,0 +1,239 @@
+package multipass
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestQuoteShellArg(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"safe", "kubectl", "kubectl"},
+		{"safe punctuation", "--node-ip=10.0.0.2,fd00::2", "--node-ip=10.0.0.2,fd00::2"},
+		{"spaces", "hello world", "'hello world'"},
+		{"double quotes", `say "hi"`, `'say "hi"'`},
+		{"single quote", "it's", `'it'\''s'`},
+		{"only single quotes", "''", `''\'''\'''`},
+		{"semicolon", "true; rm -rf /", "'true; rm -rf /'"},
+		{"command substitution", "$(whoami)", "'$(whoami)'"},
+		{"backticks and pipes", "`id` | cat && ls", "'`id` | cat && ls'"},
+		{"glob", "*.yaml", "'*.yaml'"},
+		{"newline", "a\nb", "'a\nb'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteShellArg(tt.arg); got != tt.want {
+				t.Errorf("quoteShellArg(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQuoteShellArgsRoundTrip checks that a POSIX shell parses quoted arguments
+// back into the original words
+func TestQuoteShellArgsRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	args := []string{"", "plain", "two words", `"double"`, "it's", "a;b", "$(id)", "`id`", "x && y", "*", "tab\there", "line\nbreak"}
+	script := `for arg in ` + strings.Join(quoteShellArgs(args), " ") + `; do printf '%s\0' "$arg"; done`
+
+	output, err := exec.Command(sh, "-c", script).Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+
+	var got []string
+	start := 0
+	for i, b := range output {
+		if b == 0 {
+			got = append(got, string(output[start:i]))
+			start = i + 1
+		}
+	}
+
+	if len(got) != len(args) {
+		t.Fatalf("got %d words %q, want %d", len(got), got, len(args))
+	}
+	for i := range args {
+		if got[i] != args[i] {
+			t.Errorf("word %d = %q, want %q", i, got[i], args[i])
+		}
+	}
+}
+
+func TestParseMultipassListShortRows(t *testing.T) {
+	output := "Name,State,IPv4,IPv6,Release,AllIPv4\n" +
+		"mpkube-dev,Running,10.0.0.2,fd00::2\n" +
+		"other,Stopped,--,--,Ubuntu 24.04 LTS,\n"
+
+	vms, err := parseMultipassList(output)
+	if err != nil {
+		t.Fatalf("parseMultipassList: %v", err)
+	}
+
+	want := []VM{
+		{Name: "mpkube-dev", State: "Running", IPv4: "10.0.0.2", IPv6: "fd00::2", IsK3s: true},
+		{Name: "other", State: "Stopped", IPv4: "--", IPv6: "--", Image: "Ubuntu 24.04 LTS"},
+	}
+	if !slices.Equal(vms, want) {
+		t.Errorf("parseMultipassList() = %+v, want %+v", vms, want)
+	}
+}
+
+func TestParseMultipassListReorderedColumns(t *testing.T) {
+	output := "State,Name,Release,IPv4\n" +
+		"Running,mpkube-dev,Ubuntu 24.04 LTS,10.0.0.2\n"
+
+	vms, err := parseMultipassList(output)
+	if err != nil {
+		t.Fatalf("parseMultipassList: %v", err)
+	}
+
+	want := []VM{{Name: "mpkube-dev", State: "Running", IPv4: "10.0.0.2", Image: "Ubuntu 24.04 LTS", IsK3s: true}}
+	if !slices.Equal(vms, want) {
+		t.Errorf("parseMultipassList() = %+v, want %+v", vms, want)
+	}
+}
+
+func TestParseMultipassListHeader(t *testing.T) {
+	rows := "mpkube-dev,Running,10.0.0.2,,Ubuntu 24.04 LTS,10.0.0.2\n" +
+		"other,Stopped,--,--,Ubuntu 22.04 LTS,\n"
+	want := []VM{
+		{Name: "mpkube-dev", State: "Running", IPv4: "10.0.0.2", Image: "Ubuntu 24.04 LTS", IsK3s: true},
+		{Name: "other", State: "Stopped", IPv4: "--", IPv6: "--", Image: "Ubuntu 22.04 LTS"},
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []VM
+	}{
+		{"with header", "Name,State,IPv4,IPv6,Release,AllIPv4\n" + rows, want},
+		{"lowercase header", "name,state,ipv4,ipv6,release,allipv4\n" + rows, want},
+		{"without header", rows, want},
+		{"header only", "Name,State,IPv4,IPv6,Release,AllIPv4\n", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vms, err := parseMultipassList(tt.output)
+			if err != nil {
+				t.Fatalf("parseMultipassList: %v", err)
+			}
+			if !slices.Equal(vms, tt.want) {
+				t.Errorf("parseMultipassList() = %+v, want %+v", vms, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsToWSLPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{`C:\Users\me\.kube`, "/mnt/c/Users/me/.kube", true},
+		{`d:\data`, "/mnt/d/data", true},
+		{"C:/Users/me/.kube", "/mnt/c/Users/me/.kube", true},
+		{`C:\`, "/mnt/c/", true},
+		{"C:", "/mnt/c", true},
+		{"C:foo", "", false},
+		{"/home/me/.kube", "", false},
+		{`\\server\share`, "", false},
+		{".kube/config", "", false},
+		{"1:\\x", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := windowsToWSLPath(tt.path)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("windowsToWSLPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWSLToWindowsPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/mnt/c/Users/me/.kube", `C:\Users\me\.kube`, true},
+		{"/mnt/d/data", `D:\data`, true},
+		{"/mnt/c/", `C:\`, true},
+		{"/mnt/c", `C:\`, true},
+		{"/mnt/cd/data", "", false},
+		{"/mnt/1/data", "", false},
+		{"/mnt/", "", false},
+		{"/home/me/.kube", "", false},
+		{`C:\Users\me`, "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := wslToWindowsPath(tt.path)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("wslToWindowsPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	wsl := &MultipassEnv{IsWSL: true}
+	windows := &MultipassEnv{RunningOnWindows: true}
+
+	tests := []struct {
+		name string
+		env  *MultipassEnv
+		path string
+		want string
+	}{
+		{"wsl drive path", wsl, `C:\Users\me\.kube`, "/mnt/c/Users/me/.kube"},
+		{"wsl bare drive", wsl, "C:", "/mnt/c"},
+		{"wsl linux path", wsl, "/home/me/.kube", "/home/me/.kube"},
+		{"wsl relative path", wsl, ".kube/config", ".kube/config"},
+		{"windows mnt path", windows, "/mnt/c/Users/me/.kube", `C:\Users\me\.kube`},
+		{"windows bare mnt drive", windows, "/mnt/c", `C:\`},
+		{"windows drive path", windows, `C:\Users\me\.kube`, `C:\Users\me\.kube`},
+		{"linux leaves drive paths", &MultipassEnv{}, "/mnt/c/Users/me", "/mnt/c/Users/me"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.env.LocalPath(tt.path); got != tt.want {
+				t.Errorf("LocalPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWSLNames(t *testing.T) {
+	// wsl.exe writes UTF-16LE with CRLF line endings
+	var output []byte
+	for _, r := range "Ubuntu-22.04\r\ndocker-desktop\r\n\r\n" {
+		output = append(output, byte(r), 0)
+	}
+
+	got := parseWSLNames(decodeWSLOutput(output))
+	want := []wslDistribution{{Name: "Ubuntu-22.04"}, {Name: "docker-desktop"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseWSLNames() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package multipass
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Network represents a host network that multipass can attach VMs to
+type Network struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// ListNetworks returns the networks available to multipass launch --network
+func (m *MultipassEnv) ListNetworks() ([]Network, error) {
+	output, err := m.RunMultipassCmd("networks", "--format", "csv")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w\n%s", err, output)
+	}
+
+	return parseMultipassNetworks(output)
+}
+
+// parseMultipassNetworks parses the CSV output of multipass networks, mapping columns by header
+func parseMultipassNetworks(output string) ([]Network, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(output)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse multipass networks output: %w", err)
+	}
+
+	if len(records) <= 1 {
+		return nil, nil // No networks or just header
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var networks []Network
+	for _, record := range records[1:] {
+		network := Network{
+			Name:        field(record, "name"),
+			Type:        field(record, "type"),
+			Description: field(record, "description"),
+		}
+		if network.Name == "" {
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// NetworkName returns the network name of a launch --network value, which is
+// either a bare name or a list of options such as name=en0,mode=manual
+func NetworkName(spec string) string {
+	for _, option := range strings.Split(spec, ",") {
+		if name, ok := strings.CutPrefix(option, "name="); ok {
+			return name
+		}
+	}
+	if !strings.Contains(spec, "=") {
+		return spec
+	}
+	return ""
+}
+
+// ValidateNetwork checks that the network of a launch --network value exists,
+// suggesting the closest match when it does not
+func (m *MultipassEnv) ValidateNetwork(spec string) error {
+	name := NetworkName(spec)
+	if name == "" {
+		return fmt.Errorf("invalid network %q: expected a name or name=<network>[,mode=...][,mac=...]", spec)
+	}
+
+	networks, err := m.ListNetworks()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, network := range networks {
+		if network.Name == name {
+			return nil
+		}
+		names = append(names, network.Name)
+	}
+
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		return fmt.Errorf("network %q not found, did you mean %q? (run 'multipass networks' to list networks)", name, suggestion)
+	}
+	return fmt.Errorf("network %q not found (run 'multipass networks' to list networks)", name)
+}
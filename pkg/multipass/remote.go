@@ -0,0 +1,94 @@
+package multipass
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+)
+
+// remoteHost is the SSH destination multipass runs on, see SetRemoteHost
+var remoteHost string
+
+// SetRemoteHost makes mpkube drive multipass on another machine over SSH, e.g.
+// user@host. An empty host uses the local multipass.
+func SetRemoteHost(host string) {
+	remoteHost = host
+}
+
+// RemoteHost returns the SSH destination multipass runs on, or "" for local multipass
+func RemoteHost() string {
+	return remoteHost
+}
+
+// newRemoteEnv returns an environment running multipass on host over SSH. Local
+// detection is skipped since multipass is not expected to be installed here.
+func newRemoteEnv(host string) *MultipassEnv {
+	debugf("Using multipass on %s over SSH\n", host)
+	return &MultipassEnv{
+		RemoteHost:   host,
+		MultipassCmd: "multipass",
+	}
+}
+
+// remoteCommand builds an ssh command that runs multipass with args on the
+// remote host. The remote shell parses the command line, so each arg is quoted.
+// tty allocates a terminal for interactive commands; otherwise ssh must not
+// prompt, so a missing key fails instead of hanging.
+func (m *MultipassEnv) remoteCommand(ctx context.Context, tty bool, args ...string) *exec.Cmd {
+	sshArgs := []string{"-o", "BatchMode=yes"}
+	if tty {
+		sshArgs = []string{"-t"}
+	}
+	sshArgs = append(sshArgs, m.RemoteHost, "--", "multipass")
+	sshArgs = append(sshArgs, quoteShellArgs(args)...)
+	return exec.CommandContext(ctx, "ssh", sshArgs...)
+}
+
+// copyToRemoteVM streams a local file into a VM on the remote host. multipass
+// transfer would read the path on the remote host, so the file is piped
+// through multipass exec instead.
+func (m *MultipassEnv) copyToRemoteVM(localPath string, vmName string, vmPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer f.Close()
+
+	var output bytes.Buffer
+	script := fmt.Sprintf("mkdir -p %s && cat > %s", quoteShellArg(path.Dir(vmPath)), quoteShellArg(vmPath))
+	cmd := m.command(context.Background(), "exec", vmName, "--", "sh", "-c", script)
+	cmd.Stdin = f
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s:%s: %v\nOutput: %s", localPath, vmName, vmPath, err, output.String())
+	}
+	return nil
+}
+
+// copyFromRemoteVM streams a file out of a VM on the remote host into localPath
+func (m *MultipassEnv) copyFromRemoteVM(vmName string, vmPath string, localPath string) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", localPath, err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := m.command(context.Background(), "exec", vmName, "--", "cat", vmPath)
+	cmd.Stdout = f
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	closeErr := f.Close()
+
+	if runErr != nil {
+		os.Remove(localPath)
+		return fmt.Errorf("failed to copy %s:%s to %s: %v\nOutput: %s", vmName, vmPath, localPath, runErr, stderr.String())
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", localPath, closeErr)
+	}
+	return nil
+}
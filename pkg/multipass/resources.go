@@ -0,0 +1,136 @@
+package multipass
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrInsufficientResources is returned when the host lacks the memory or disk to launch a VM
+var ErrInsufficientResources = errors.New("not enough host resources")
+
+// Resources that can run out when launching a VM
+const (
+	ResourceMemory = "memory"
+	ResourceDisk   = "disk"
+)
+
+// resourceErrorPatterns are lower-cased fragments of the messages multipass and
+// the hypervisors it drives print when the host runs out of a resource
+var resourceErrorPatterns = []struct {
+	pattern  string
+	resource string
+}{
+	{"cannot allocate memory", ResourceMemory},
+	{"not enough memory", ResourceMemory},
+	{"insufficient memory", ResourceMemory},
+	{"out of memory", ResourceMemory},
+	{"no space left on device", ResourceDisk},
+	{"not enough disk space", ResourceDisk},
+	{"not enough space", ResourceDisk},
+	{"insufficient disk", ResourceDisk},
+	{"disk is full", ResourceDisk},
+}
+
+// ExhaustedResource returns ResourceMemory or ResourceDisk when multipass
+// output reports that the host ran out of it, or "" otherwise
+func ExhaustedResource(output string) string {
+	output = strings.ToLower(output)
+	for _, p := range resourceErrorPatterns {
+		if strings.Contains(output, p.pattern) {
+			return p.resource
+		}
+	}
+	return ""
+}
+
+// Usage is the memory and disk in bytes and the CPUs allocated to running VMs
+type Usage struct {
+	Memory  int64
+	Disk    int64
+	CPUs    int
+	Running int
+}
+
+// RunningUsage returns the resources allocated to running VMs and how many there are
+func (m *MultipassEnv) RunningUsage() (Usage, error) {
+	vms, err := m.ListVMs()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var usage Usage
+	for _, vm := range vms {
+		if vm.State != "Running" {
+			continue
+		}
+		info, err := m.GetVMInfo(vm.Name)
+		if err != nil {
+			return Usage{}, err
+		}
+		usage.Memory += info.MemoryTotal
+		usage.Disk += info.DiskTotal
+		usage.CPUs += info.CPUs
+		usage.Running++
+	}
+
+	return usage, nil
+}
+
+// HostMemory returns the physical memory in bytes of the machine multipass runs
+// its VMs on. It is unknown for a remote multipass host.
+func (m *MultipassEnv) HostMemory() (int64, error) {
+	switch {
+	case m.RemoteHost != "":
+		return 0, fmt.Errorf("the memory of remote host %s is unknown", m.RemoteHost)
+	case m.RunningOnWindows, m.IsWSL && strings.HasSuffix(m.MultipassCmd, ".exe"):
+		// Windows multipass, whose VMs use the memory of Windows rather than of WSL
+		return commandBytes("powershell.exe", "-NoProfile", "-Command", "(Get-CimInstance Win32_ComputerSystem).TotalPhysicalMemory")
+	case runtime.GOOS == "darwin":
+		return commandBytes("sysctl", "-n", "hw.memsize")
+	default:
+		return procMemTotal()
+	}
+}
+
+// commandBytes runs a command that prints a number of bytes and parses it
+func commandBytes(name string, args ...string) (int64, error) {
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get host memory from %s: %w", name, err)
+	}
+	n, err := strconv.ParseInt(string(bytes.TrimSpace(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse host memory from %s: %w", name, err)
+	}
+	return n, nil
+}
+
+// procMemTotal returns MemTotal from /proc/meminfo in bytes
+func procMemTotal() (int64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get host memory: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. "MemTotal:       16318412 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse MemTotal in /proc/meminfo: %w", err)
+			}
+			return kb << 10, nil
+		}
+	}
+	return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the animation frames drawn on a terminal
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+const (
+	// frameInterval is how often the spinner redraws on a terminal
+	frameInterval = 100 * time.Millisecond
+	// dotInterval is how often a progress dot is printed when not on a terminal
+	dotInterval = 2 * time.Second
+)
+
+// Spinner shows progress for a long-running operation. On a terminal it animates
+// in place; otherwise it prints the message followed by periodic dots.
+type Spinner struct {
+	w       io.Writer
+	msg     string
+	tty     bool
+	enabled bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewSpinner creates a spinner that writes msg to w. A disabled spinner prints nothing.
+func NewSpinner(w io.Writer, msg string, enabled bool) *Spinner {
+	return &Spinner{
+		w:       w,
+		msg:     msg,
+		tty:     IsTerminal(w),
+		enabled: enabled,
+	}
+}
+
+// Start begins drawing the spinner in the background
+func (s *Spinner) Start() {
+	if !s.enabled {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+
+	if !s.tty {
+		fmt.Fprint(s.w, s.msg)
+		go s.run(dotInterval, func(int) { fmt.Fprint(s.w, ".") })
+		return
+	}
+
+	go s.run(frameInterval, func(i int) {
+		fmt.Fprintf(s.w, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], s.msg)
+	})
+}
+
+// run calls draw every interval until the spinner is stopped
+func (s *Spinner) run(interval time.Duration, draw func(int)) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		draw(i)
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop ends the spinner and prints result on the same line
+func (s *Spinner) Stop(result string) {
+	if !s.enabled || s.done == nil {
+		return
+	}
+
+	close(s.done)
+	s.wg.Wait()
+	s.done = nil
+
+	if s.tty {
+		fmt.Fprintf(s.w, "\r%s %s\n", s.msg, result)
+		return
+	}
+	fmt.Fprintf(s.w, " %s\n", result)
+}
+
+// IsTerminal reports whether w is an interactive terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
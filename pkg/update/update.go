@@ -0,0 +1,225 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latestReleaseURL is the GitHub API endpoint for the latest mpkube release
+const latestReleaseURL = "https://api.github.com/repos/rodneyxr/mpkube/releases/latest"
+
+// checksumsAsset is the release asset listing the SHA-256 checksum of every binary
+const checksumsAsset = "checksums.txt"
+
+// httpClient is used for all update requests
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Release is a published mpkube release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the release asset with the given name
+func (r *Release) asset(name string) (*Asset, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return &a, nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no asset %s", r.TagName, name)
+}
+
+// LatestRelease fetches the latest published release from GitHub
+func LatestRelease() (*Release, error) {
+	req, err := http.NewRequest(http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: GitHub returned %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+
+	return &release, nil
+}
+
+// IsNewer reports whether version latest is newer than current. Versions are
+// compared as major.minor.patch with an optional leading "v".
+func IsNewer(current string, latest string) bool {
+	c, l := parseVersion(current), parseVersion(latest)
+	for i := range c {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion splits a version such as v1.2.3 into its numeric parts,
+// ignoring any pre-release or build suffix
+func parseVersion(version string) [3]int {
+	var parts [3]int
+
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	for i, field := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
+
+// AssetName returns the name of the release binary for the current OS and architecture
+func AssetName() string {
+	name := fmt.Sprintf("mpkube_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Apply downloads the binary for this platform from release, verifies its
+// checksum and replaces the running executable with it
+func Apply(release *Release) error {
+	name := AssetName()
+
+	binary, err := release.asset(name)
+	if err != nil {
+		return err
+	}
+	checksums, err := release.asset(checksumsAsset)
+	if err != nil {
+		return err
+	}
+
+	sums, err := download(checksums.URL)
+	if err != nil {
+		return err
+	}
+	want, err := findChecksum(sums, name)
+	if err != nil {
+		return err
+	}
+
+	data, err := download(binary.URL)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+
+	return replaceExecutable(data)
+}
+
+// download fetches the body of url
+func download(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// findChecksum returns the checksum of name from a checksums file in sha256sum format
+func findChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum found for %s", name)
+}
+
+// replaceExecutable atomically replaces the running executable with data
+func replaceExecutable(data []byte) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+
+	// The new binary is written next to the old one so the rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+
+	// Windows cannot replace a running executable, but it can rename it out of the way
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("failed to move the old binary aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+
+	return nil
+}